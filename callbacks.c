@@ -0,0 +1,501 @@
+// Glue code for WiredTiger callbacks, mostly for extensions that are implemented in Go.
+// Each extension is wrapped in a C structure that embeds WiredTiger interface as its first
+// field, and holds cgo.Handle of the Go implementation. Functions in this file can't be
+// defined in cgo preambles, because Go files that export functions to C can only have
+// declarations in their preambles.
+#include <errno.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <wiredtiger.h>
+#include "_cgo_export.h"
+#include "callbacks.h"
+
+static void _set_error(wt_session_error *e, int error, const char *message) {
+	e->error = error;
+	strncpy(e->message, message, sizeof(e->message) - 1);
+	e->message[sizeof(e->message) - 1] = '\0';
+}
+
+// WT_EVENT_HANDLER implementation, that records last error of each session in
+// wt_session_error structure, if session has one. Messages are still written to stderr,
+// same as the default event handler does.
+static int _session_handle_error(
+	WT_EVENT_HANDLER *handler, WT_SESSION *session, int error, const char *message) {
+	if (session != NULL && session->app_private != NULL) {
+		_set_error((wt_session_error *)session->app_private, error, message);
+	}
+	fprintf(stderr, "%s\n", message);
+	return 0;
+}
+
+WT_EVENT_HANDLER wt_session_event_handler = {
+	.handle_error = _session_handle_error,
+};
+
+// WT_EVENT_HANDLER implementation for wiredtiger_open call. Errors of sessions that have
+// wt_session_error are recorded same as by wt_session_event_handler, and the rest are
+// recorded in the connection's last_error.
+static int _conn_handle_error(
+	WT_EVENT_HANDLER *handler, WT_SESSION *session, int error, const char *message) {
+	if (session != NULL && session->app_private != NULL) {
+		return _session_handle_error(handler, session, error, message);
+	}
+	wt_conn_event_handler *h = (wt_conn_event_handler *)handler;
+	pthread_mutex_lock(&h->mu);
+	_set_error(&h->last_error, error, message);
+	pthread_mutex_unlock(&h->mu);
+	fprintf(stderr, "%s\n", message);
+	return 0;
+}
+
+wt_conn_event_handler *wt_conn_event_handler_new(void) {
+	wt_conn_event_handler *h = calloc(1, sizeof(wt_conn_event_handler));
+	h->iface.handle_error = _conn_handle_error;
+	pthread_mutex_init(&h->mu, NULL);
+	return h;
+}
+
+// Must only be called once connection is closed, or once wiredtiger_open call has failed.
+void wt_conn_event_handler_free(wt_conn_event_handler *handler) {
+	pthread_mutex_destroy(&handler->mu);
+	free(handler);
+}
+
+// Returns copy of the last error message, if it matches `error`, or NULL otherwise. Returned
+// message must be freed by the caller. Last error is always cleared.
+char *wt_conn_event_handler_last_error(wt_conn_event_handler *handler, int error) {
+	char *message = NULL;
+	pthread_mutex_lock(&handler->mu);
+	if (handler->last_error.error != 0 && handler->last_error.error == error) {
+		message = strdup(handler->last_error.message);
+	}
+	handler->last_error.error = 0;
+	pthread_mutex_unlock(&handler->mu);
+	return message;
+}
+
+// WT_EXTRACTOR implementation.
+typedef struct {
+	WT_EXTRACTOR iface;
+	uintptr_t handle;
+} wt_go_extractor;
+
+static int _go_extractor_extract(
+	WT_EXTRACTOR *extractor, WT_SESSION *session,
+	const WT_ITEM *key, const WT_ITEM *value, WT_CURSOR *result_cursor) {
+	wt_go_extractor *e = (wt_go_extractor *)extractor;
+	return goExtractorExtract(
+		e->handle,
+		(void *)key->data, key->size,
+		(void *)value->data, value->size,
+		result_cursor);
+}
+
+static int _go_extractor_terminate(WT_EXTRACTOR *extractor, WT_SESSION *session) {
+	wt_go_extractor *e = (wt_go_extractor *)extractor;
+	goHandleDelete(e->handle);
+	free(e);
+	return 0;
+}
+
+int wt_conn_add_extractor(
+	WT_CONNECTION *connection, const char *name, uintptr_t handle) {
+	wt_go_extractor *e = calloc(1, sizeof(wt_go_extractor));
+	if (e == NULL) {
+		return WT_ERROR;
+	}
+	e->iface.extract = _go_extractor_extract;
+	e->iface.terminate = _go_extractor_terminate;
+	e->handle = handle;
+	int r = connection->add_extractor(connection, name, &e->iface, NULL);
+	if (r != 0) {
+		free(e);
+	}
+	return r;
+}
+
+int wt_extractor_emit(WT_CURSOR *result_cursor, const void *data, size_t size) {
+	WT_ITEM item;
+	item.data = data;
+	item.size = size;
+	result_cursor->set_key(result_cursor, &item);
+	return result_cursor->insert(result_cursor);
+}
+
+// WT_COLLATOR implementation.
+typedef struct {
+	WT_COLLATOR iface;
+	uintptr_t handle;
+} wt_go_collator;
+
+static int _go_collator_compare(
+	WT_COLLATOR *collator, WT_SESSION *session,
+	const WT_ITEM *v1, const WT_ITEM *v2, int *cmp) {
+	wt_go_collator *c = (wt_go_collator *)collator;
+	*cmp = goCollatorCompare(
+		c->handle,
+		(void *)v1->data, v1->size,
+		(void *)v2->data, v2->size);
+	return 0;
+}
+
+static int _go_collator_terminate(WT_COLLATOR *collator, WT_SESSION *session) {
+	wt_go_collator *c = (wt_go_collator *)collator;
+	goHandleDelete(c->handle);
+	free(c);
+	return 0;
+}
+
+int wt_conn_add_collator(
+	WT_CONNECTION *connection, const char *name, uintptr_t handle) {
+	wt_go_collator *c = calloc(1, sizeof(wt_go_collator));
+	if (c == NULL) {
+		return WT_ERROR;
+	}
+	c->iface.compare = _go_collator_compare;
+	c->iface.terminate = _go_collator_terminate;
+	c->handle = handle;
+	int r = connection->add_collator(connection, name, &c->iface, NULL);
+	if (r != 0) {
+		free(c);
+	}
+	return r;
+}
+
+// WT_COMPRESSOR implementation.
+typedef struct {
+	WT_COMPRESSOR iface;
+	uintptr_t handle;
+} wt_go_compressor;
+
+static int _go_compressor_compress(
+	WT_COMPRESSOR *compressor, WT_SESSION *session,
+	uint8_t *src, size_t src_len,
+	uint8_t *dst, size_t dst_len,
+	size_t *result_lenp, int *compression_failed) {
+	wt_go_compressor *c = (wt_go_compressor *)compressor;
+	return goCompressorCompress(
+		c->handle, src, src_len, dst, dst_len, result_lenp, compression_failed);
+}
+
+static int _go_compressor_decompress(
+	WT_COMPRESSOR *compressor, WT_SESSION *session,
+	uint8_t *src, size_t src_len,
+	uint8_t *dst, size_t dst_len,
+	size_t *result_lenp) {
+	wt_go_compressor *c = (wt_go_compressor *)compressor;
+	return goCompressorDecompress(c->handle, src, src_len, dst, dst_len, result_lenp);
+}
+
+static int _go_compressor_pre_size(
+	WT_COMPRESSOR *compressor, WT_SESSION *session,
+	uint8_t *src, size_t src_len, size_t *result_lenp) {
+	wt_go_compressor *c = (wt_go_compressor *)compressor;
+	return goCompressorPreSize(c->handle, src_len, result_lenp);
+}
+
+static int _go_compressor_terminate(WT_COMPRESSOR *compressor, WT_SESSION *session) {
+	wt_go_compressor *c = (wt_go_compressor *)compressor;
+	goHandleDelete(c->handle);
+	free(c);
+	return 0;
+}
+
+int wt_conn_add_compressor(
+	WT_CONNECTION *connection, const char *name, uintptr_t handle) {
+	wt_go_compressor *c = calloc(1, sizeof(wt_go_compressor));
+	if (c == NULL) {
+		return WT_ERROR;
+	}
+	c->iface.compress = _go_compressor_compress;
+	c->iface.decompress = _go_compressor_decompress;
+	c->iface.pre_size = _go_compressor_pre_size;
+	c->iface.terminate = _go_compressor_terminate;
+	c->handle = handle;
+	int r = connection->add_compressor(connection, name, &c->iface, NULL);
+	if (r != 0) {
+		free(c);
+	}
+	return r;
+}
+
+// WT_ENCRYPTOR implementation. Encryptor that is added to the connection holds handle
+// of EncryptorSpec, and customized encryptors, created for each key, hold handles of
+// Encryptors.
+typedef struct {
+	WT_ENCRYPTOR iface;
+	WT_CONNECTION *connection;
+	uintptr_t handle;
+} wt_go_encryptor;
+
+static int _go_encryptor_encrypt(
+	WT_ENCRYPTOR *encryptor, WT_SESSION *session,
+	uint8_t *src, size_t src_len,
+	uint8_t *dst, size_t dst_len,
+	size_t *result_lenp) {
+	wt_go_encryptor *e = (wt_go_encryptor *)encryptor;
+	return goEncryptorEncrypt(e->handle, src, src_len, dst, dst_len, result_lenp);
+}
+
+static int _go_encryptor_decrypt(
+	WT_ENCRYPTOR *encryptor, WT_SESSION *session,
+	uint8_t *src, size_t src_len,
+	uint8_t *dst, size_t dst_len,
+	size_t *result_lenp) {
+	wt_go_encryptor *e = (wt_go_encryptor *)encryptor;
+	return goEncryptorDecrypt(e->handle, src, src_len, dst, dst_len, result_lenp);
+}
+
+static int _go_encryptor_sizing(
+	WT_ENCRYPTOR *encryptor, WT_SESSION *session, size_t *expansion_constantp) {
+	wt_go_encryptor *e = (wt_go_encryptor *)encryptor;
+	return goEncryptorSizing(e->handle, expansion_constantp);
+}
+
+static int _go_encryptor_config_get(
+	WT_EXTENSION_API *api, WT_SESSION *session, WT_CONFIG_ARG *config,
+	const char *key, WT_CONFIG_ITEM *value) {
+	int r = api->config_get(api, session, config, key, value);
+	if (r == WT_NOTFOUND) {
+		value->str = "";
+		value->len = 0;
+		return 0;
+	}
+	return r;
+}
+
+static int _go_encryptor_customize(
+	WT_ENCRYPTOR *encryptor, WT_SESSION *session,
+	WT_CONFIG_ARG *encrypt_config, WT_ENCRYPTOR **customp) {
+	wt_go_encryptor *e = (wt_go_encryptor *)encryptor;
+	WT_EXTENSION_API *api = e->connection->get_extension_api(e->connection);
+	WT_CONFIG_ITEM keyid, secretkey;
+	int r = _go_encryptor_config_get(api, session, encrypt_config, "keyid", &keyid);
+	if (r != 0) {
+		return r;
+	}
+	r = _go_encryptor_config_get(api, session, encrypt_config, "secretkey", &secretkey);
+	if (r != 0) {
+		return r;
+	}
+	wt_go_encryptor *custom = calloc(1, sizeof(wt_go_encryptor));
+	if (custom == NULL) {
+		return WT_ERROR;
+	}
+	r = goEncryptorCustomize(
+		e->handle,
+		(void *)keyid.str, keyid.len,
+		(void *)secretkey.str, secretkey.len,
+		&custom->handle);
+	if (r != 0) {
+		free(custom);
+		return r;
+	}
+	custom->iface = e->iface;
+	custom->connection = e->connection;
+	*customp = &custom->iface;
+	return 0;
+}
+
+static int _go_encryptor_terminate(WT_ENCRYPTOR *encryptor, WT_SESSION *session) {
+	wt_go_encryptor *e = (wt_go_encryptor *)encryptor;
+	goHandleDelete(e->handle);
+	free(e);
+	return 0;
+}
+
+int wt_conn_add_encryptor(
+	WT_CONNECTION *connection, const char *name, uintptr_t handle) {
+	wt_go_encryptor *e = calloc(1, sizeof(wt_go_encryptor));
+	if (e == NULL) {
+		return WT_ERROR;
+	}
+	e->iface.encrypt = _go_encryptor_encrypt;
+	e->iface.decrypt = _go_encryptor_decrypt;
+	e->iface.sizing = _go_encryptor_sizing;
+	e->iface.customize = _go_encryptor_customize;
+	e->iface.terminate = _go_encryptor_terminate;
+	e->connection = connection;
+	e->handle = handle;
+	int r = connection->add_encryptor(connection, name, &e->iface, NULL);
+	if (r != 0) {
+		free(e);
+	}
+	return r;
+}
+
+// WT_FILE_SYSTEM and WT_FILE_HANDLE implementations.
+typedef struct {
+	WT_FILE_SYSTEM iface;
+	uintptr_t handle;
+} wt_go_fs;
+
+typedef struct {
+	WT_FILE_HANDLE iface;
+	uintptr_t handle;
+} wt_go_fh;
+
+static int _go_fh_close(WT_FILE_HANDLE *file_handle, WT_SESSION *session) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	int r = goFileClose(fh->handle);
+	free(fh->iface.name);
+	free(fh);
+	return r;
+}
+
+static int _go_fh_lock(WT_FILE_HANDLE *file_handle, WT_SESSION *session, bool lock) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileLock(fh->handle, lock ? 1 : 0);
+}
+
+static int _go_fh_read(
+	WT_FILE_HANDLE *file_handle, WT_SESSION *session,
+	wt_off_t offset, size_t len, void *buf) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileRead(fh->handle, offset, len, buf);
+}
+
+static int _go_fh_size(WT_FILE_HANDLE *file_handle, WT_SESSION *session, wt_off_t *sizep) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileSize(fh->handle, sizep);
+}
+
+static int _go_fh_sync(WT_FILE_HANDLE *file_handle, WT_SESSION *session) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileSync(fh->handle);
+}
+
+static int _go_fh_truncate(WT_FILE_HANDLE *file_handle, WT_SESSION *session, wt_off_t len) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileTruncate(fh->handle, len);
+}
+
+static int _go_fh_write(
+	WT_FILE_HANDLE *file_handle, WT_SESSION *session,
+	wt_off_t offset, size_t len, const void *buf) {
+	wt_go_fh *fh = (wt_go_fh *)file_handle;
+	return goFileWrite(fh->handle, offset, len, (void *)buf);
+}
+
+static int _go_fs_open_file(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session,
+	const char *name, WT_FS_OPEN_FILE_TYPE file_type, uint32_t flags,
+	WT_FILE_HANDLE **file_handlep) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	wt_go_fh *fh = calloc(1, sizeof(wt_go_fh));
+	if (fh == NULL) {
+		return ENOMEM;
+	}
+	fh->iface.name = strdup(name);
+	if (fh->iface.name == NULL) {
+		free(fh);
+		return ENOMEM;
+	}
+	int r = goFSOpen(
+		fs->handle, (char *)name,
+		file_type == WT_FS_OPEN_FILE_TYPE_DIRECTORY, flags, &fh->handle);
+	if (r != 0) {
+		free(fh->iface.name);
+		free(fh);
+		return r;
+	}
+	fh->iface.file_system = file_system;
+	fh->iface.close = _go_fh_close;
+	fh->iface.fh_lock = _go_fh_lock;
+	fh->iface.fh_read = _go_fh_read;
+	fh->iface.fh_size = _go_fh_size;
+	fh->iface.fh_sync = _go_fh_sync;
+	fh->iface.fh_truncate = _go_fh_truncate;
+	fh->iface.fh_write = _go_fh_write;
+	*file_handlep = &fh->iface;
+	return 0;
+}
+
+static int _go_fs_directory_list(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session,
+	const char *directory, const char *prefix, char ***dirlistp, uint32_t *countp) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	return goFSList(fs->handle, (char *)directory, (char *)prefix, 0, dirlistp, countp);
+}
+
+static int _go_fs_directory_list_single(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session,
+	const char *directory, const char *prefix, char ***dirlistp, uint32_t *countp) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	return goFSList(fs->handle, (char *)directory, (char *)prefix, 1, dirlistp, countp);
+}
+
+static int _go_fs_directory_list_free(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session, char **dirlist, uint32_t count) {
+	if (dirlist == NULL) {
+		return 0;
+	}
+	for (uint32_t i = 0; i < count; i++) {
+		free(dirlist[i]);
+	}
+	free(dirlist);
+	return 0;
+}
+
+static int _go_fs_exist(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session, const char *name, bool *existp) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	int exist = 0;
+	int r = goFSExists(fs->handle, (char *)name, &exist);
+	*existp = exist != 0;
+	return r;
+}
+
+static int _go_fs_remove(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session, const char *name, uint32_t flags) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	return goFSRemove(fs->handle, (char *)name);
+}
+
+static int _go_fs_rename(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session,
+	const char *from, const char *to, uint32_t flags) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	return goFSRename(fs->handle, (char *)from, (char *)to);
+}
+
+static int _go_fs_size(
+	WT_FILE_SYSTEM *file_system, WT_SESSION *session, const char *name, wt_off_t *sizep) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	return goFSSize(fs->handle, (char *)name, sizep);
+}
+
+static int _go_fs_terminate(WT_FILE_SYSTEM *file_system, WT_SESSION *session) {
+	wt_go_fs *fs = (wt_go_fs *)file_system;
+	goHandleDelete(fs->handle);
+	free(fs);
+	return 0;
+}
+
+int wt_conn_set_file_system(WT_CONNECTION *connection, uintptr_t handle) {
+	wt_go_fs *fs = calloc(1, sizeof(wt_go_fs));
+	if (fs == NULL) {
+		return WT_ERROR;
+	}
+	fs->iface.fs_directory_list = _go_fs_directory_list;
+	fs->iface.fs_directory_list_single = _go_fs_directory_list_single;
+	fs->iface.fs_directory_list_free = _go_fs_directory_list_free;
+	fs->iface.fs_exist = _go_fs_exist;
+	fs->iface.fs_open_file = _go_fs_open_file;
+	fs->iface.fs_remove = _go_fs_remove;
+	fs->iface.fs_rename = _go_fs_rename;
+	fs->iface.fs_size = _go_fs_size;
+	fs->iface.terminate = _go_fs_terminate;
+	fs->handle = handle;
+	int r = connection->set_file_system(connection, &fs->iface, NULL);
+	if (r != 0) {
+		free(fs);
+	}
+	return r;
+}
+
+// Entry point for the 'local' extension that is loaded by Open call, see extension.go.
+int wt_go_extension_init(WT_CONNECTION *connection, WT_CONFIG_ARG *config) {
+	return goExtensionInit(connection);
+}
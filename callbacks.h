@@ -0,0 +1,49 @@
+#ifndef WT_GO_CALLBACKS_H
+#define WT_GO_CALLBACKS_H
+
+#include <pthread.h>
+#include <stdint.h>
+#include <wiredtiger.h>
+
+// Last error that WiredTiger has reported for a session. Stored in WT_SESSION::app_private
+// field, by the event handler.
+typedef struct {
+	int error;
+	char message[512];
+} wt_session_error;
+
+// Event handler of a connection. Errors of sessions that aren't opened with
+// wt_session_event_handler, i.e. errors of wiredtiger_open call, WT_CONNECTION methods and
+// internal WiredTiger threads, are stored in last_error. Guarded by mutex, since internal
+// threads can report errors concurrently.
+typedef struct {
+	WT_EVENT_HANDLER iface;
+	pthread_mutex_t mu;
+	wt_session_error last_error;
+} wt_conn_event_handler;
+
+// Result of a cursor call that reads key and/or value. Results are returned by value,
+// instead of through pointers to Go memory. This way Go side doesn't need to allocate
+// anything on the heap for these calls.
+typedef struct {
+	int r;
+	const void *key;
+	size_t key_size;
+	const void *value;
+	size_t value_size;
+} wt_cursor_kv;
+
+// Implemented in callbacks.c.
+extern WT_EVENT_HANDLER wt_session_event_handler;
+wt_conn_event_handler *wt_conn_event_handler_new(void);
+void wt_conn_event_handler_free(wt_conn_event_handler *handler);
+char *wt_conn_event_handler_last_error(wt_conn_event_handler *handler, int error);
+int wt_conn_add_extractor(WT_CONNECTION *connection, const char *name, uintptr_t handle);
+int wt_extractor_emit(WT_CURSOR *result_cursor, const void *data, size_t size);
+int wt_conn_add_collator(WT_CONNECTION *connection, const char *name, uintptr_t handle);
+int wt_conn_add_compressor(WT_CONNECTION *connection, const char *name, uintptr_t handle);
+int wt_conn_add_encryptor(WT_CONNECTION *connection, const char *name, uintptr_t handle);
+int wt_conn_set_file_system(WT_CONNECTION *connection, uintptr_t handle);
+int wt_go_extension_init(WT_CONNECTION *connection, WT_CONFIG_ARG *config);
+
+#endif
@@ -0,0 +1,142 @@
+package wt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zviadm/wt/keys"
+)
+
+// Codec encodes and decodes values of type T for use as keys or values of a Table.
+// When used for keys, encoding must preserve ordering of values, when compared with
+// bytes.Compare, for range scans to work as expected.
+type Codec[T any] interface {
+	// Append appends encoding of `v` to `dst`.
+	Append(dst []byte, v T) ([]byte, error)
+	// Decode decodes value from `data`. `data` is only valid during the call, thus
+	// Decode must copy it, if it needs to retain it.
+	Decode(data []byte) (T, error)
+}
+
+// StringCodec encodes strings as raw bytes. Preserves ordering.
+type StringCodec struct{}
+
+// Append implements Codec interface.
+func (StringCodec) Append(dst []byte, v string) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+// Decode implements Codec interface.
+func (StringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// BytesCodec stores byte slices as is. Preserves ordering.
+type BytesCodec struct{}
+
+// Append implements Codec interface.
+func (BytesCodec) Append(dst []byte, v []byte) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+// Decode implements Codec interface.
+func (BytesCodec) Decode(data []byte) ([]byte, error) {
+	return copyBuffer(data), nil
+}
+
+// Uint64Codec encodes integers as fixed 8 byte big-endian values. Preserves ordering.
+type Uint64Codec struct{}
+
+// Append implements Codec interface.
+func (Uint64Codec) Append(dst []byte, v uint64) ([]byte, error) {
+	return keys.AppendUint64(dst, v), nil
+}
+
+// Decode implements Codec interface.
+func (Uint64Codec) Decode(data []byte) (uint64, error) {
+	v, _, err := keys.ReadUint64(data)
+	return v, err
+}
+
+// Int64Codec encodes integers as fixed 8 byte big-endian values, with sign bit flipped.
+// Preserves ordering.
+type Int64Codec struct{}
+
+// Append implements Codec interface.
+func (Int64Codec) Append(dst []byte, v int64) ([]byte, error) {
+	return keys.AppendInt64(dst, v), nil
+}
+
+// Decode implements Codec interface.
+func (Int64Codec) Decode(data []byte) (int64, error) {
+	v, _, err := keys.ReadInt64(data)
+	return v, err
+}
+
+// BinaryCodec encodes values using their encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler implementations. PT must be pointer type of T:
+//
+//	BinaryCodec[time.Time, *time.Time]{}
+type BinaryCodec[T any, PT interface {
+	*T
+	encoding.BinaryUnmarshaler
+}] struct{}
+
+// Append implements Codec interface.
+func (BinaryCodec[T, PT]) Append(dst []byte, v T) ([]byte, error) {
+	m, ok := any(v).(encoding.BinaryMarshaler)
+	if !ok {
+		m, ok = any(&v).(encoding.BinaryMarshaler)
+	}
+	if !ok {
+		return dst, fmt.Errorf("%T doesn't implement encoding.BinaryMarshaler", v)
+	}
+	data, err := m.MarshalBinary()
+	return append(dst, data...), err
+}
+
+// Decode implements Codec interface.
+func (BinaryCodec[T, PT]) Decode(data []byte) (T, error) {
+	var v T
+	err := PT(&v).UnmarshalBinary(copyBuffer(data))
+	return v, err
+}
+
+// JSONCodec encodes values using encoding/json package. Doesn't preserve ordering, thus
+// it is only suitable for values.
+type JSONCodec[T any] struct{}
+
+// Append implements Codec interface.
+func (JSONCodec[T]) Append(dst []byte, v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return append(dst, data...), err
+}
+
+// Decode implements Codec interface.
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// GobCodec encodes values using encoding/gob package. Each value is encoded together with
+// its type information, thus GobCodec is simple to use, but not very space efficient.
+// Doesn't preserve ordering, thus it is only suitable for values.
+type GobCodec[T any] struct{}
+
+// Append implements Codec interface.
+func (GobCodec[T]) Append(dst []byte, v T) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	err := gob.NewEncoder(buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+// Decode implements Codec interface.
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
@@ -0,0 +1,49 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+// AddCollator performs WT_CONNECTION::add_collator call, registering `compare` function
+// as a collator under `name`. Data sources can then be created with custom key ordering,
+// using DataSourceCfg{Collator: name}. `compare` must return a negative number, zero or
+// a positive number, if `a` is less than, equal to or greater than `b`. Its arguments point
+// to `C` memory and are only valid during the call. It can be called concurrently from
+// multiple goroutines and it must always produce the same ordering for the same keys.
+//
+// Collator name is stored in data source metadata, but the collator itself isn't. Thus
+// collator must be registered again every time connection is opened, before any data sources
+// that use it are accessed. Otherwise opening cursors on such data sources fails with
+// an error, since WiredTiger can't find the collator.
+func (c *Connection) AddCollator(name string, compare func(a, b []byte) int) error {
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+	h := cgo.NewHandle(compare)
+	r := C.wt_conn_add_collator(c.c, nameC, C.uintptr_t(h))
+	if r != 0 {
+		h.Delete()
+	}
+	return c.connError(r, "add_collator", name)
+}
+
+//export goCollatorCompare
+func goCollatorCompare(
+	handle C.uintptr_t,
+	a unsafe.Pointer, aSize C.size_t,
+	b unsafe.Pointer, bSize C.size_t) C.int {
+	compare := cgo.Handle(handle).Value().(func(a, b []byte) int)
+	cmp := compare(bytesFromC(a, aSize), bytesFromC(b, bSize))
+	if cmp < 0 {
+		return -1
+	} else if cmp > 0 {
+		return 1
+	}
+	return 0
+}
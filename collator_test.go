@@ -0,0 +1,89 @@
+package wt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollator(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "wt_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dbDir)
+
+	caseInsensitive := func(a, b []byte) int {
+		return bytes.Compare(bytes.ToLower(a), bytes.ToLower(b))
+	}
+	c, err := Open(dbDir, ConnCfg{Create: True})
+	require.NoError(t, err)
+	err = c.AddCollator("nocase", caseInsensitive)
+	require.NoError(t, err)
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+	err = s.Create("table:nocase", DataSourceCfg{Collator: "nocase"})
+	require.NoError(t, err)
+
+	c1, err := s.OpenCursor("table:nocase")
+	require.NoError(t, err)
+	for _, k := range []string{"Cherry", "apple", "Banana"} {
+		require.NoError(t, c1.Insert([]byte(k), []byte("v")))
+	}
+	var keys []string
+	for c1.Next() == nil {
+		k, err := c1.Key()
+		require.NoError(t, err)
+		keys = append(keys, string(k))
+	}
+	require.EqualValues(t, []string{"apple", "Banana", "Cherry"}, keys)
+
+	match, err := c1.SearchNear([]byte("BANANA"))
+	require.NoError(t, err)
+	require.EqualValues(t, MatchedExact, match)
+	k, err := c1.Key()
+	require.NoError(t, err)
+	require.EqualValues(t, "Banana", string(k))
+
+	match, err = c1.SearchNear([]byte("c"))
+	require.NoError(t, err)
+	k, err = c1.Key()
+	require.NoError(t, err)
+	switch match {
+	case MatchedSmaller:
+		require.EqualValues(t, "Banana", string(k))
+	case MatchedLarger:
+		require.EqualValues(t, "Cherry", string(k))
+	default:
+		t.Fatalf("unexpected match: %v, key: %s", match, k)
+	}
+
+	match, err = c1.SearchNear([]byte("zzz"))
+	require.NoError(t, err)
+	require.EqualValues(t, MatchedSmaller, match)
+	k, err = c1.Key()
+	require.NoError(t, err)
+	require.EqualValues(t, "Cherry", string(k))
+	require.NoError(t, c1.Close())
+	require.NoError(t, s.Close())
+	require.NoError(t, c.Close())
+
+	// Table can't be used after reopen, until collator is registered again.
+	c, err = Open(dbDir)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, c.Close()) }()
+	s, err = c.OpenSession()
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	_, err = s.OpenCursor("table:nocase")
+	require.Error(t, err)
+	err = c.AddCollator("nocase", caseInsensitive)
+	require.NoError(t, err)
+	c1, err = s.OpenCursor("table:nocase")
+	require.NoError(t, err)
+	defer c1.Close()
+	match, err = c1.SearchNear([]byte("APPLE"))
+	require.NoError(t, err)
+	require.EqualValues(t, MatchedExact, match)
+}
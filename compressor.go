@@ -0,0 +1,108 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// Compressor is a Go implementation of WT_COMPRESSOR. It can be added to a connection
+// with Connection.AddCompressor call, or with ConnCfg.Compressors config. Methods can be
+// called concurrently from multiple goroutines.
+type Compressor interface {
+	// Compress compresses `src` into `dst` and returns size of compressed data. If compressed
+	// data doesn't fit into `dst`, Compress must return 0 and nil error, in which case data
+	// is stored uncompressed.
+	Compress(dst, src []byte) (int, error)
+	// Decompress decompresses `src` into `dst` and returns size of decompressed data. `src`
+	// is exactly the data that was produced by Compress call and `dst` is large enough to
+	// hold all of the decompressed data.
+	Decompress(dst, src []byte) (int, error)
+	// PreSize returns maximum size of compressed data for `srcLen` bytes of input.
+	PreSize(srcLen int) int
+}
+
+// compressHeaderLen is size of the header that is stored in front of compressed data.
+// WiredTiger may pad compressed data before passing it to WT_COMPRESSOR::decompress,
+// thus actual length of compressed data is stored in the header.
+const compressHeaderLen = 8
+
+// AddCompressor performs WT_CONNECTION::add_compressor call, registering Go implementation
+// of a compressor under `name`. Data sources can then be created with DataSourceCfg{BlockCompressor: name}.
+// Compressor is released when connection is closed.
+//
+// Similar to collators, compressor must be added every time connection is opened, before
+// accessing any data sources that use it.
+func (c *Connection) AddCompressor(name string, compressor Compressor) error {
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+	h := cgo.NewHandle(compressor)
+	r := C.wt_conn_add_compressor(c.c, nameC, C.uintptr_t(h))
+	if r != 0 {
+		h.Delete()
+	}
+	return c.connError(r, "add_compressor", name)
+}
+
+//export goCompressorCompress
+func goCompressorCompress(
+	handle C.uintptr_t,
+	src unsafe.Pointer, srcLen C.size_t,
+	dst unsafe.Pointer, dstLen C.size_t,
+	resultLen *C.size_t, compressionFailed *C.int) C.int {
+	compressor := cgo.Handle(handle).Value().(Compressor)
+	if dstLen <= compressHeaderLen {
+		*compressionFailed = 1
+		return 0
+	}
+	dstB := bytesFromC(dst, dstLen)
+	n, err := compressor.Compress(dstB[compressHeaderLen:], bytesFromC(src, srcLen))
+	if err != nil {
+		return errorCodeC(err)
+	}
+	if n <= 0 {
+		*compressionFailed = 1
+		return 0
+	}
+	binary.LittleEndian.PutUint64(dstB, uint64(n))
+	*resultLen = C.size_t(compressHeaderLen + n)
+	*compressionFailed = 0
+	return 0
+}
+
+//export goCompressorDecompress
+func goCompressorDecompress(
+	handle C.uintptr_t,
+	src unsafe.Pointer, srcLen C.size_t,
+	dst unsafe.Pointer, dstLen C.size_t,
+	resultLen *C.size_t) C.int {
+	compressor := cgo.Handle(handle).Value().(Compressor)
+	srcB := bytesFromC(src, srcLen)
+	if len(srcB) < compressHeaderLen {
+		return C.int(ErrError)
+	}
+	n := binary.LittleEndian.Uint64(srcB)
+	if n > uint64(len(srcB)-compressHeaderLen) {
+		return C.int(ErrError)
+	}
+	m, err := compressor.Decompress(
+		bytesFromC(dst, dstLen), srcB[compressHeaderLen:compressHeaderLen+int(n)])
+	if err != nil {
+		return errorCodeC(err)
+	}
+	*resultLen = C.size_t(m)
+	return 0
+}
+
+//export goCompressorPreSize
+func goCompressorPreSize(handle C.uintptr_t, srcLen C.size_t, resultLen *C.size_t) C.int {
+	compressor := cgo.Handle(handle).Value().(Compressor)
+	*resultLen = C.size_t(compressHeaderLen + compressor.PreSize(int(srcLen)))
+	return 0
+}
@@ -0,0 +1,95 @@
+package wt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt/compressors/lz4"
+	"github.com/zviadm/wt/compressors/zstd"
+)
+
+func TestCompressors(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "wt_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dbDir)
+
+	zstdC, err := zstd.New(0)
+	require.NoError(t, err)
+	cfg := ConnCfg{
+		Create: True,
+		Log:    "enabled,compressor=" + zstd.Name,
+		Compressors: map[string]Compressor{
+			zstd.Name: zstdC,
+			lz4.Name:  lz4.New(),
+		},
+	}
+	c, err := Open(dbDir, cfg)
+	require.NoError(t, err)
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+
+	marker := []byte("wiredtiger-compression-marker ")
+	value := bytes.Repeat(marker, 100)
+	nValues := 1000
+	for _, name := range []string{zstd.Name, lz4.Name} {
+		err := s.Create("table:"+name, DataSourceCfg{BlockCompressor: name})
+		require.NoError(t, err)
+		c1, err := s.OpenCursor("table:" + name)
+		require.NoError(t, err)
+		for i := 0; i < nValues; i++ {
+			err := c1.Insert([]byte("key"+strconv.Itoa(i)), value)
+			require.NoError(t, err)
+		}
+		require.NoError(t, c1.Close())
+	}
+	require.NoError(t, s.Close())
+	require.NoError(t, c.Close())
+
+	// Both data files and the log must contain compressed data only.
+	for _, name := range []string{zstd.Name, lz4.Name} {
+		data, err := ioutil.ReadFile(filepath.Join(dbDir, name+".wt"))
+		require.NoError(t, err)
+		require.Less(t, len(data), nValues*len(value)/10, name)
+	}
+	logFiles, err := filepath.Glob(filepath.Join(dbDir, "WiredTigerLog.*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, logFiles)
+	for _, logFile := range logFiles {
+		data, err := ioutil.ReadFile(logFile)
+		require.NoError(t, err)
+		// Uncompressed log would contain 100 markers for each value.
+		require.Less(t, bytes.Count(data, marker), 10*nValues, logFile)
+	}
+
+	// Compressors must be added again after reopen.
+	c, err = Open(dbDir, cfg)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, c.Close()) }()
+	s, err = c.OpenSession()
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	for _, name := range []string{zstd.Name, lz4.Name} {
+		c1, err := s.OpenCursor("table:" + name)
+		require.NoError(t, err)
+		for i := 0; i < nValues; i++ {
+			v, err := c1.ReadValue([]byte("key" + strconv.Itoa(i)))
+			require.NoError(t, err)
+			require.EqualValues(t, value, v)
+		}
+		require.NoError(t, c1.Close())
+	}
+}
+
+func TestAppendExtensionsConfig(t *testing.T) {
+	require.EqualValues(t, extensionsConfig+"\x00", appendExtensionsConfig("\x00"))
+	require.EqualValues(t,
+		"create=1,"+extensionsConfig+"\x00", appendExtensionsConfig(configC([]ConnCfg{{
+			Create:      True,
+			Compressors: map[string]Compressor{lz4.Name: lz4.New()},
+		}})))
+}
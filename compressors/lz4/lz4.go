@@ -0,0 +1,44 @@
+// Package lz4 implements wt.Compressor using pure Go implementation of LZ4 block
+// compression from github.com/pierrec/lz4.
+package lz4
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// Name is the conventional name to register compressor with.
+const Name = "lz4"
+
+// Compressor implements wt.Compressor interface.
+type Compressor struct {
+	pool sync.Pool
+}
+
+// New creates new Compressor.
+func New() *Compressor {
+	return &Compressor{pool: sync.Pool{New: func() interface{} { return &lz4.Compressor{} }}}
+}
+
+// Compress implements wt.Compressor interface.
+func (c *Compressor) Compress(dst, src []byte) (int, error) {
+	compressor := c.pool.Get().(*lz4.Compressor)
+	defer c.pool.Put(compressor)
+	n, err := compressor.CompressBlock(src, dst)
+	if errors.Is(err, lz4.ErrInvalidSourceShortBuffer) {
+		return 0, nil // Doesn't fit into `dst`.
+	}
+	return n, err
+}
+
+// Decompress implements wt.Compressor interface.
+func (c *Compressor) Decompress(dst, src []byte) (int, error) {
+	return lz4.UncompressBlock(src, dst)
+}
+
+// PreSize implements wt.Compressor interface.
+func (c *Compressor) PreSize(srcLen int) int {
+	return lz4.CompressBlockBound(srcLen)
+}
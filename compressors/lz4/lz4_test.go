@@ -0,0 +1,31 @@
+package lz4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	c := New()
+	for _, src := range [][]byte{
+		bytes.Repeat([]byte("wiredtiger "), 1000),
+		[]byte("a"),
+	} {
+		dst := make([]byte, c.PreSize(len(src)))
+		n, err := c.Compress(dst, src)
+		require.NoError(t, err)
+		require.NotZero(t, n)
+		out := make([]byte, len(src))
+		m, err := c.Decompress(out, dst[:n])
+		require.NoError(t, err)
+		require.EqualValues(t, src, out[:m])
+	}
+
+	// Compress must fail gracefully, if data doesn't fit.
+	src := []byte("incompressible data")
+	n, err := c.Compress(make([]byte, 4), src)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
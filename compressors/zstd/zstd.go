@@ -0,0 +1,80 @@
+// Package zstd implements wt.Compressor using pure Go implementation of Zstandard
+// compression from github.com/klauspost/compress.
+package zstd
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Name is the conventional name to register compressor with.
+const Name = "zstd"
+
+// Compressor implements wt.Compressor interface. Each concurrent Compress call uses its
+// own encoder from a pool, since WiredTiger compresses pages from multiple eviction and
+// checkpoint threads at the same time.
+type Compressor struct {
+	encoders sync.Pool
+	dec      *zstd.Decoder
+}
+
+// New creates new Compressor with given compression level. Level follows zstd command
+// line levels, 0 means default level.
+func New(level int) (*Compressor, error) {
+	encLevel := zstd.SpeedDefault
+	if level != 0 {
+		encLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	encOpts := []zstd.EOption{zstd.WithEncoderLevel(encLevel), zstd.WithEncoderConcurrency(1)}
+	enc, err := zstd.NewWriter(nil, encOpts...)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	if err != nil {
+		return nil, err
+	}
+	c := &Compressor{dec: dec}
+	c.encoders.New = func() interface{} {
+		// Options are already validated above, thus NewWriter can't fail.
+		enc, _ := zstd.NewWriter(nil, encOpts...)
+		return enc
+	}
+	c.encoders.Put(enc)
+	return c, nil
+}
+
+// Compress implements wt.Compressor interface.
+func (c *Compressor) Compress(dst, src []byte) (int, error) {
+	enc := c.encoders.Get().(*zstd.Encoder)
+	defer c.encoders.Put(enc)
+	out := enc.EncodeAll(src, dst[:0])
+	if len(out) > len(dst) {
+		return 0, nil // Doesn't fit, EncodeAll had to allocate new buffer.
+	}
+	return len(out), nil
+}
+
+// Decompress implements wt.Compressor interface.
+func (c *Compressor) Decompress(dst, src []byte) (int, error) {
+	out, err := c.dec.DecodeAll(src, dst[:0])
+	if err != nil {
+		return 0, err
+	}
+	if len(out) > len(dst) {
+		return 0, errors.New("zstd: decompressed data doesn't fit into destination buffer")
+	}
+	return len(out), nil
+}
+
+// PreSize implements wt.Compressor interface. Mirrors ZSTD_compressBound.
+func (c *Compressor) PreSize(srcLen int) int {
+	const maxMargin = 128 << 10
+	bound := srcLen + srcLen>>8
+	if srcLen < maxMargin {
+		bound += (maxMargin - srcLen) >> 11
+	}
+	return bound
+}
@@ -0,0 +1,66 @@
+package zstd
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	c := newTestCompressor(t)
+	for _, src := range [][]byte{
+		bytes.Repeat([]byte("wiredtiger "), 1000),
+		[]byte("a"),
+	} {
+		dst := make([]byte, c.PreSize(len(src)))
+		n, err := c.Compress(dst, src)
+		require.NoError(t, err)
+		require.NotZero(t, n)
+		out := make([]byte, len(src))
+		m, err := c.Decompress(out, dst[:n])
+		require.NoError(t, err)
+		require.EqualValues(t, src, out[:m])
+	}
+
+	// Compress must fail gracefully, if data doesn't fit.
+	src := []byte("incompressible data")
+	n, err := c.Compress(make([]byte, 4), src)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestCompressorConcurrent(t *testing.T) {
+	c := newTestCompressor(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := bytes.Repeat([]byte("wiredtiger "+strconv.Itoa(i)), 1000)
+			for j := 0; j < 100; j++ {
+				dst := make([]byte, c.PreSize(len(src)))
+				n, err := c.Compress(dst, src)
+				if err != nil || n == 0 {
+					t.Errorf("compress: %d, %v", n, err)
+					return
+				}
+				out := make([]byte, len(src))
+				m, err := c.Decompress(out, dst[:n])
+				if err != nil || !bytes.Equal(src, out[:m]) {
+					t.Errorf("decompress: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func newTestCompressor(t *testing.T) *Compressor {
+	c, err := New(0)
+	require.NoError(t, err)
+	return c
+}
@@ -40,7 +40,8 @@ func toSnakeCase(str string) string {
 // string so it can be directly passed to C functions as const char*.
 // Config must be []ConfigStruct of type, with length of 0 or 1, otherwise this
 // function will panic. Transforms CamelCase config fields to snake_case and
-// skips fields with default values.
+// skips fields with default values. Fields tagged with `wt:"-"` aren't part of
+// configuration string and are skipped too.
 func configC(config interface{}) string {
 	v := reflect.ValueOf(config)
 	if config == nil || v.IsNil() || v.Len() == 0 {
@@ -54,6 +55,9 @@ func configC(config interface{}) string {
 	cfgParts := make([]string, 0, vt.NumField())
 	for idx := 0; idx < vt.NumField(); idx++ {
 		vf := vt.Field(idx)
+		if vf.Tag.Get("wt") == "-" {
+			continue
+		}
 		name := toSnakeCase(vf.Name)
 
 		vv := v.Field(idx)
@@ -83,6 +87,9 @@ func configC(config interface{}) string {
 			cfgParts = append(cfgParts, name+"=\""+vvv+"\"")
 			break
 		case reflect.Slice:
+			if vv.Len() == 0 {
+				break
+			}
 			vvv := make([]string, vv.Len())
 			for idx := range vvv {
 				vvv[idx] = vv.Index(idx).String()
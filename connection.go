@@ -2,7 +2,7 @@ package wt
 
 /*
 #include <stdlib.h>
-#include <wiredtiger.h>
+#include "callbacks.h"
 
 // Expose WT methods accessed through function pointers:
 int wt_conn_close(
@@ -23,12 +23,26 @@ int wt_conn_open_session(
 import "C"
 
 import (
+	"os"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
 // Connection is a wrapper for WT_CONNECTION class.
 type Connection struct {
-	c *C.WT_CONNECTION
+	c            *C.WT_CONNECTION
+	eventHandler *C.wt_conn_event_handler
+	scratchDir   string
+
+	notifierMu sync.Mutex
+	notifier   *syncNotifier
+
+	// Buffers for last errors of sessions that are still open. Sessions that aren't closed
+	// explicitly are closed by WT_CONNECTION::close call, thus their buffers are freed by
+	// Connection.Close.
+	sessionErrorsMu sync.Mutex
+	sessionErrors   map[unsafe.Pointer]struct{}
 }
 
 // StatisticsEnum enumerates configuration options for 'statistics'.
@@ -49,22 +63,57 @@ type ConnCfg struct {
 	CacheSize       int
 	Checkpoint      string
 	Create          wtBool
+	Encryption      string
+	InMemory        wtBool
 	Log             string
 	SessionMax      int
 	Statistics      []StatisticsEnum
 	StatisticsLog   string
 	TransactionSync string
+
+	// Compressors are added to the connection during wiredtiger_open call, mapped by
+	// their names. Compressors that are used for the log must be added this way, since
+	// log is opened before wiredtiger_open returns.
+	Compressors map[string]Compressor `wt:"-"`
+	// Encryptors are added to the connection during wiredtiger_open call, mapped by their
+	// names. Encryptor that is configured with Encryption config must be added this way.
+	Encryptors map[string]EncryptorSpec `wt:"-"`
+	// FileSystem replaces default file system for the connection, see FileSystem.
+	FileSystem FileSystem `wt:"-"`
 }
 
-// Open performs wiredtiger_open call.
+// Open performs wiredtiger_open call. Empty `path` means a new scratch directory, that
+// is removed when connection is closed. This is mostly useful together with InMemory
+// config, i.e. Open("", ConnCfg{InMemory: True}), since in-memory databases still need
+// a home directory, even though they don't write anything to it.
+//
+// In-memory databases can't evict data from the cache, thus once cache becomes full,
+// operations start to fail with ErrCacheAll error, see ErrCacheAll for details.
 func Open(path string, cfg ...ConnCfg) (*Connection, error) {
+	c := &Connection{}
+	if path == "" {
+		var err error
+		if path, err = os.MkdirTemp("", "wt_"); err != nil {
+			return nil, err
+		}
+		c.scratchDir = path
+	}
 	pathC := C.CString(path)
 	defer C.free(unsafe.Pointer(pathC))
-	cfgC := C.CString(configC(cfg))
+	config := configC(cfg)
+	if len(cfg) > 0 && hasExtensions(cfg[0]) {
+		unlock := pendExtensions(cfg[0])
+		defer unlock()
+		config = appendExtensionsConfig(config)
+	}
+	cfgC := C.CString(config)
 	defer C.free(unsafe.Pointer(cfgC))
-	c := &Connection{}
-	if r := C.wiredtiger_open(pathC, nil, cfgC, &c.c); r != 0 {
-		return nil, wtError(r)
+	c.eventHandler = C.wt_conn_event_handler_new()
+	if r := C.wiredtiger_open(pathC, &c.eventHandler.iface, cfgC, &c.c); r != 0 {
+		err := c.connError(r, "wiredtiger_open", path)
+		c.freeEventHandler()
+		c.removeScratchDir()
+		return nil, err
 	}
 	return c, nil
 }
@@ -74,14 +123,53 @@ type ConnCloseCfg struct {
 	LeakMemory wtBool
 }
 
-// Close performs WT_CONNECTION::close call.
+// Close performs WT_CONNECTION::close call. Scratch directory, if connection was opened
+// with an empty path, is removed even if close fails, since connection can't be used
+// after close anyway.
 func (c *Connection) Close(cfg ...ConnCloseCfg) error {
+	c.stopSyncNotifier()
 	cfgC := configC(cfg)
-	if r := C.wt_conn_close(c.c, cfgC); r != 0 {
-		return wtError(r)
+	r := C.wt_conn_close(c.c, cfgC)
+	err := c.connError(r, "close", "")
+	c.freeSessionErrors()
+	c.freeEventHandler()
+	errRemove := c.removeScratchDir()
+	if err != nil {
+		return err
 	}
 	c.c = nil
-	return nil
+	return errRemove
+}
+
+func (c *Connection) freeEventHandler() {
+	if c.eventHandler == nil {
+		return
+	}
+	C.wt_conn_event_handler_free(c.eventHandler)
+	c.eventHandler = nil
+}
+
+// lastErrorMessage returns message of the last error that WiredTiger has reported to the
+// connection event handler, if it matches `errorCode`, same as Session.lastErrorMessage.
+func (c *Connection) lastErrorMessage(errorCode C.int) string {
+	if c.eventHandler == nil {
+		return ""
+	}
+	msgC := C.wt_conn_event_handler_last_error(c.eventHandler, errorCode)
+	if msgC == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(msgC))
+	return C.GoString(msgC)
+}
+
+func (c *Connection) removeScratchDir() error {
+	if c.scratchDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(c.scratchDir)
+	c.scratchDir = ""
+	return err
 }
 
 // SessionCfg mirrors options for WT_CONNECTION::open_session call.
@@ -92,9 +180,40 @@ type SessionCfg struct {
 // OpenSession performs WT_CONNECTION::open_session call.
 func (c *Connection) OpenSession(cfg ...SessionCfg) (*Session, error) {
 	cfgC := configC(cfg)
-	s := &Session{}
-	if r := C.wt_conn_open_session(c.c, nil, cfgC, &s.s); r != 0 {
-		return nil, wtError(r)
+	s := &Session{conn: c}
+	if r := C.wt_conn_open_session(c.c, &C.wt_session_event_handler, cfgC, &s.s); r != 0 {
+		return nil, c.connError(r, "open_session", strings.TrimSuffix(cfgC, "\x00"))
 	}
+	s.s.app_private = c.allocSessionError()
+	s.lastError = (*C.wt_session_error)(s.s.app_private)
 	return s, nil
 }
+
+func (c *Connection) allocSessionError() unsafe.Pointer {
+	p := C.calloc(1, C.sizeof_wt_session_error)
+	c.sessionErrorsMu.Lock()
+	defer c.sessionErrorsMu.Unlock()
+	if c.sessionErrors == nil {
+		c.sessionErrors = make(map[unsafe.Pointer]struct{})
+	}
+	c.sessionErrors[p] = struct{}{}
+	return p
+}
+
+func (c *Connection) freeSessionError(p unsafe.Pointer) {
+	c.sessionErrorsMu.Lock()
+	defer c.sessionErrorsMu.Unlock()
+	if _, ok := c.sessionErrors[p]; ok {
+		delete(c.sessionErrors, p)
+		C.free(p)
+	}
+}
+
+func (c *Connection) freeSessionErrors() {
+	c.sessionErrorsMu.Lock()
+	defer c.sessionErrorsMu.Unlock()
+	for p := range c.sessionErrors {
+		C.free(p)
+	}
+	c.sessionErrors = nil
+}
@@ -1,11 +1,13 @@
-package wt
+package wt_test
 
 import (
 	"io/ioutil"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
 )
 
 func TestOpen(t *testing.T) {
@@ -13,21 +15,73 @@ func TestOpen(t *testing.T) {
 	require.NoError(t, err)
 	defer os.RemoveAll(dbDir)
 
-	_, err = Open(dbDir)
+	_, err = wt.Open(dbDir)
 	require.Error(t, err)
 
-	c, err := Open(dbDir, ConnCfg{
-		Create:        True,
+	c, err := wt.Open(dbDir, wt.ConnCfg{
+		Create:        wt.True,
 		Log:           "enabled,compressor=snappy",
-		Statistics:    []StatisticsEnum{StatsAll, StatsClear},
+		Statistics:    []wt.StatisticsEnum{wt.StatsAll, wt.StatsClear},
 		StatisticsLog: "wait=30",
 	})
 	require.NoError(t, err)
 	err = c.Close()
 	require.NoError(t, err)
 
-	c, err = Open(dbDir)
+	c, err = wt.Open(dbDir)
 	require.NoError(t, err)
-	err = c.Close(ConnCloseCfg{LeakMemory: True}) // Leak memory, but this is ok, just in testing.
+	err = c.Close(wt.ConnCloseCfg{LeakMemory: wt.True}) // Leak memory, but this is ok, just in testing.
 	require.NoError(t, err)
 }
+
+func TestOpenInMemory(t *testing.T) {
+	c, err := wt.Open("", wt.ConnCfg{
+		InMemory:   wt.True,
+		CacheSize:  1 << 20,
+		Statistics: []wt.StatisticsEnum{wt.StatsFast},
+	})
+	require.NoError(t, err)
+	scratchDir := c.ScratchDir()
+	require.NotEmpty(t, scratchDir)
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+	err = s.Create("table:test_table")
+	require.NoError(t, err)
+	cc, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+
+	// Writes must fail once cache becomes full.
+	value := make([]byte, 1024)
+	for i := 0; i < 10000; i++ {
+		err = cc.Insert([]byte(strconv.Itoa(i)), value)
+		if err != nil {
+			break
+		}
+	}
+	require.EqualValues(t, wt.ErrCacheAll, wt.ErrCode(err))
+	stats, err := s.CacheStats()
+	require.NoError(t, err)
+	require.EqualValues(t, 1<<20, stats.BytesMax)
+	require.Greater(t, stats.Usage(), 0.5)
+
+	require.NoError(t, cc.Close())
+	require.NoError(t, s.Close())
+	require.NoError(t, c.Close())
+	_, err = os.Stat(scratchDir)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSessionErrorsFreed(t *testing.T) {
+	c, err := wt.Open("", wt.ConnCfg{InMemory: wt.True})
+	require.NoError(t, err)
+	s1, err := c.OpenSession()
+	require.NoError(t, err)
+	_, err = c.OpenSession()
+	require.NoError(t, err)
+	require.Equal(t, 2, c.SessionErrors())
+	require.NoError(t, s1.Close())
+	require.Equal(t, 1, c.SessionErrors())
+	// Second session is closed implicitly by connection close.
+	require.NoError(t, c.Close())
+	require.Equal(t, 0, c.SessionErrors())
+}
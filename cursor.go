@@ -1,8 +1,10 @@
 package wt
 
 /*
+#include <errno.h>
 #include <stdlib.h>
-#include <wiredtiger.h>
+#include <string.h>
+#include "callbacks.h"
 
 // Expose WT methods accessed through function pointers:
 int wt_cursor_close(WT_CURSOR *cursor) {
@@ -32,12 +34,120 @@ int wt_cursor_get_value(WT_CURSOR *cursor, WT_ITEM *item) {
 	return cursor->get_value(cursor, item);
 }
 
+wt_cursor_kv wt_cursor_get_key_item(WT_CURSOR *cursor) {
+	wt_cursor_kv kv = {0};
+	WT_ITEM item;
+	kv.r = cursor->get_key(cursor, &item);
+	if (kv.r == 0) {
+		kv.key = item.data;
+		kv.key_size = item.size;
+	}
+	return kv;
+}
+wt_cursor_kv wt_cursor_get_value_item(WT_CURSOR *cursor) {
+	wt_cursor_kv kv = {0};
+	WT_ITEM item;
+	kv.r = cursor->get_value(cursor, &item);
+	if (kv.r == 0) {
+		kv.value = item.data;
+		kv.value_size = item.size;
+	}
+	return kv;
+}
+wt_cursor_kv wt_cursor_move_and_get(WT_CURSOR *cursor, int reverse) {
+	wt_cursor_kv kv = {0};
+	WT_ITEM key, value;
+	kv.r = reverse ? cursor->prev(cursor) : cursor->next(cursor);
+	if (kv.r != 0) {
+		return kv;
+	}
+	if ((kv.r = cursor->get_key(cursor, &key)) != 0) {
+		return kv;
+	}
+	if ((kv.r = cursor->get_value(cursor, &value)) != 0) {
+		return kv;
+	}
+	kv.key = key.data;
+	kv.key_size = key.size;
+	kv.value = value.data;
+	kv.value_size = value.size;
+	return kv;
+}
+
 int wt_cursor_next(WT_CURSOR *cursor) {
     return cursor->next(cursor);
 }
 int wt_cursor_prev(WT_CURSOR *cursor) {
     return cursor->prev(cursor);
 }
+// WT_CURSOR::largest_key is only available starting with WiredTiger 11.0.
+#if WIREDTIGER_VERSION_MAJOR >= 11
+#define WT_HAS_LARGEST_KEY 1
+#else
+#define WT_HAS_LARGEST_KEY 0
+#endif
+int wt_cursor_largest_key(WT_CURSOR *cursor) {
+#if WT_HAS_LARGEST_KEY
+	return cursor->largest_key(cursor);
+#else
+	return ENOTSUP;
+#endif
+}
+// Fallback for WT_CURSOR::largest_key. Reads the largest key with WT_CURSOR::prev call on a
+// fresh cursor. Unless session already has a running transaction, it is done in a
+// read-uncommitted transaction, so that keys of in-progress transactions are visible, same
+// as with WT_CURSOR::largest_key. Key is copied into malloc'ed memory, that caller must free.
+wt_cursor_kv wt_cursor_largest_key_copy(WT_CURSOR *cursor, int in_tx) {
+	wt_cursor_kv kv = {0};
+	WT_SESSION *session = cursor->session;
+	WT_CURSOR *fresh;
+	WT_ITEM key;
+	int r;
+	if (!in_tx) {
+		kv.r = session->begin_transaction(session, "isolation=read-uncommitted");
+		if (kv.r != 0) {
+			return kv;
+		}
+	}
+	kv.r = session->open_cursor(session, cursor->uri, NULL, "raw", &fresh);
+	if (kv.r == 0) {
+		kv.r = fresh->prev(fresh);
+		if (kv.r == 0) {
+			kv.r = fresh->get_key(fresh, &key);
+		}
+		if (kv.r == 0) {
+			void *data = malloc(key.size > 0 ? key.size : 1);
+			if (data == NULL) {
+				kv.r = ENOMEM;
+			} else {
+				memcpy(data, key.data, key.size);
+				kv.key = data;
+				kv.key_size = key.size;
+			}
+		}
+		r = fresh->close(fresh);
+		if (kv.r == 0) {
+			kv.r = r;
+		}
+	}
+	if (!in_tx) {
+		r = session->rollback_transaction(session, NULL);
+		if (kv.r == 0) {
+			kv.r = r;
+		}
+	}
+	if (kv.r != 0 && kv.key != NULL) {
+		free((void *)kv.key);
+		kv.key = NULL;
+	}
+	return kv;
+}
+int wt_cursor_compare(WT_CURSOR *cursor, WT_CURSOR *other, int *comparep) {
+	return cursor->compare(cursor, other, comparep);
+}
+int wt_cursor_equals(WT_CURSOR *cursor, WT_CURSOR *other, int *equalp) {
+	return cursor->equals(cursor, other, equalp);
+}
 int wt_cursor_search(
 	WT_CURSOR *cursor,
 	const void *key, size_t key_size) {
@@ -91,10 +201,92 @@ int wt_cursor_remove_and_reset(
 	}
 	return cursor->reset(cursor);
 }
+int wt_cursor_reserve_and_reset(
+	WT_CURSOR *cursor,
+	const void *key, size_t key_size) {
+	_cursor_set_key(cursor, key, key_size);
+	int r = cursor->reserve(cursor);
+	if (r != 0) {
+		return r;
+	}
+	return cursor->reset(cursor);
+}
+int wt_cursor_insert_batch(
+	WT_CURSOR *cursor,
+	const uint8_t *data, const size_t *sizes, size_t n,
+	size_t *insertedp) {
+	for (size_t i = 0; i < n; i++) {
+		size_t key_size = sizes[2*i];
+		size_t value_size = sizes[2*i+1];
+		_cursor_set_key(cursor, data, key_size);
+		data += key_size;
+		_cursor_set_value(cursor, data, value_size);
+		data += value_size;
+		int r = cursor->insert(cursor);
+		if (r != 0) {
+			*insertedp = i;
+			return r;
+		}
+	}
+	*insertedp = n;
+	return 0;
+}
+
+static void _put_uint32(uint8_t *p, uint32_t v) {
+	p[0] = (uint8_t)v;
+	p[1] = (uint8_t)(v >> 8);
+	p[2] = (uint8_t)(v >> 16);
+	p[3] = (uint8_t)(v >> 24);
+}
+// Reads up to max_count key/value pairs into buf, moving cursor forward or backward. Each pair
+// is encoded as: <key size: uint32 LE><value size: uint32 LE><key><value>.
+int wt_cursor_read_batch(
+	WT_CURSOR *cursor, int reverse,
+	uint8_t *buf, size_t buf_size, size_t max_count,
+	size_t *countp, size_t *usedp, int *fullp) {
+	size_t count = 0, used = 0;
+	int r = 0;
+	*fullp = 0;
+	while (count < max_count) {
+		r = reverse ? cursor->prev(cursor) : cursor->next(cursor);
+		if (r != 0) {
+			break;
+		}
+		WT_ITEM key, value;
+		if ((r = cursor->get_key(cursor, &key)) != 0) {
+			break;
+		}
+		if ((r = cursor->get_value(cursor, &value)) != 0) {
+			break;
+		}
+		size_t size = 8 + key.size + value.size;
+		if (size > buf_size - used) {
+			// Step back, so that this pair is returned by the next call. If cursor was
+			// not positioned before, stepping back resets it, which is what we want.
+			r = reverse ? cursor->next(cursor) : cursor->prev(cursor);
+			if (r == WT_NOTFOUND) {
+				r = 0;
+			}
+			*fullp = 1;
+			break;
+		}
+		_put_uint32(buf + used, (uint32_t)key.size);
+		_put_uint32(buf + used + 4, (uint32_t)value.size);
+		memcpy(buf + used + 8, key.data, key.size);
+		memcpy(buf + used + 8 + key.size, value.data, value.size);
+		used += size;
+		count++;
+	}
+	*countp = count;
+	*usedp = used;
+	return r;
+}
 */
 import "C"
 
 import (
+	"encoding/binary"
+	"errors"
 	"unsafe"
 )
 
@@ -107,20 +299,24 @@ const (
 // Cursor is a wrapper for WT_CURSOR class. Cursor exposes WT_CURSOR methods in a way
 // to make it safe and efficient for Go<->CGO integration.
 type Cursor struct {
-	c *C.WT_CURSOR
+	c       *C.WT_CURSOR
+	s       *Session
+	iterErr error
 }
 
-// Close performs WT_CURSOR::close call.
+// Close performs WT_CURSOR::close call. WT_CURSOR handle is invalid after close, even if
+// close fails, thus cursor is no longer counted by Session.OpenCursors either way.
 func (c *Cursor) Close() error {
 	r := C.wt_cursor_close(c.c)
 	c.c = nil
-	return wtError(r)
+	c.s.cursors--
+	return c.cursorError(r, "close", nil)
 }
 
 // Reset performs WT_CURSOR::reset call.
 func (c *Cursor) Reset() error {
 	r := C.wt_cursor_reset(c.c)
-	return wtError(r)
+	return c.cursorError(r, "reset", nil)
 }
 
 // UnsafeKey returns date returned by WT_CURSOR::get_key call. This call doesn't copy the data
@@ -129,7 +325,7 @@ func (c *Cursor) Reset() error {
 func (c *Cursor) UnsafeKey() ([]byte, error) {
 	var item C.WT_ITEM
 	if r := C.wt_cursor_get_key(c.c, &item); r != 0 {
-		return nil, wtError(r)
+		return nil, c.cursorError(r, "get_key", nil)
 	}
 	return (*[goArrayMaxLen]byte)(item.data)[:item.size:item.size], nil
 }
@@ -146,7 +342,7 @@ func (c *Cursor) Key() ([]byte, error) {
 func (c *Cursor) UnsafeValue() ([]byte, error) {
 	var item C.WT_ITEM
 	if r := C.wt_cursor_get_value(c.c, &item); r != 0 {
-		return nil, wtError(r)
+		return nil, c.cursorError(r, "get_value", nil)
 	}
 	if item.size == 0 {
 		return nil, nil
@@ -160,23 +356,130 @@ func (c *Cursor) Value() ([]byte, error) {
 	return copyBuffer(r), err
 }
 
+// KeyInto appends data returned by WT_CURSOR::get_key call to `dst` and returns the
+// resulting slice. Doesn't allocate any memory as long as `dst` has enough capacity.
+func (c *Cursor) KeyInto(dst []byte) ([]byte, error) {
+	kv := C.wt_cursor_get_key_item(c.c)
+	if kv.r != 0 {
+		return dst, c.cursorError(kv.r, "get_key", nil)
+	}
+	return appendC(dst, kv.key, kv.key_size), nil
+}
+
+// ValueInto appends data returned by WT_CURSOR::get_value call to `dst` and returns the
+// resulting slice. Doesn't allocate any memory as long as `dst` has enough capacity.
+func (c *Cursor) ValueInto(dst []byte) ([]byte, error) {
+	kv := C.wt_cursor_get_value_item(c.c)
+	if kv.r != 0 {
+		return dst, c.cursorError(kv.r, "get_value", nil)
+	}
+	return appendC(dst, kv.value, kv.value_size), nil
+}
+
+// NextInto performs WT_CURSOR::next call and appends key and value of the next item
+// to `kbuf` and `vbuf`, using a single CGO call. Doesn't allocate any memory as long as
+// `kbuf` and `vbuf` have enough capacity. To reuse buffers, pass them in as `kbuf[:0]` and
+// `vbuf[:0]`.
+func (c *Cursor) NextInto(kbuf, vbuf []byte) (key, value []byte, err error) {
+	return c.moveInto(0, kbuf, vbuf)
+}
+
+// PrevInto is same as NextInto, except it performs WT_CURSOR::prev call.
+func (c *Cursor) PrevInto(kbuf, vbuf []byte) (key, value []byte, err error) {
+	return c.moveInto(1, kbuf, vbuf)
+}
+
+// moveOp returns name of the WT_CURSOR method that is used to move the cursor.
+func moveOp(reverse C.int) string {
+	if reverse != 0 {
+		return "prev"
+	}
+	return "next"
+}
+
+func (c *Cursor) moveInto(reverse C.int, kbuf, vbuf []byte) ([]byte, []byte, error) {
+	kv := C.wt_cursor_move_and_get(c.c, reverse)
+	if kv.r != 0 {
+		return kbuf, vbuf, c.cursorError(kv.r, moveOp(reverse), nil)
+	}
+	return appendC(kbuf, kv.key, kv.key_size), appendC(vbuf, kv.value, kv.value_size), nil
+}
+
 // Next performs WT_CURSOR::next call.
 func (c *Cursor) Next() error {
 	r := C.wt_cursor_next(c.c)
-	return wtError(r)
+	return c.cursorError(r, "next", nil)
 }
 
 // Prev performs WT_CURSOR::prev call.
 func (c *Cursor) Prev() error {
 	r := C.wt_cursor_prev(c.c)
-	return wtError(r)
+	return c.cursorError(r, "prev", nil)
+}
+
+// LargestKey returns copy of the largest key using WT_CURSOR::largest_key call. Unlike Prev
+// call on a reset cursor, it ignores visibility rules, thus it doesn't block on in-progress
+// transactions, but it can return key that is not yet committed or that is already removed.
+// Returns ErrNotFound if table is empty. Cursor is reset after this call.
+//
+// WiredTiger versions before 11.0 don't have WT_CURSOR::largest_key call. With these versions,
+// largest key is read using Prev call on a separate cursor, in a read-uncommitted transaction,
+// thus keys of in-progress transactions are still returned. However, if session already has
+// a running transaction, its isolation level is used instead.
+func (c *Cursor) LargestKey() ([]byte, error) {
+	if C.WT_HAS_LARGEST_KEY == 0 {
+		return c.largestKeyCopy()
+	}
+	if r := C.wt_cursor_largest_key(c.c); r != 0 {
+		return nil, c.cursorError(r, "largest_key", nil)
+	}
+	k, err := c.Key()
+	if err != nil {
+		_ = c.Reset()
+		return nil, err
+	}
+	return k, c.Reset()
+}
+
+func (c *Cursor) largestKeyCopy() ([]byte, error) {
+	if err := c.Reset(); err != nil {
+		return nil, err
+	}
+	kv := C.wt_cursor_largest_key_copy(c.c, boolC(c.s.InTx()))
+	if kv.r != 0 {
+		return nil, c.cursorError(kv.r, "largest_key", nil)
+	}
+	defer C.free(unsafe.Pointer(kv.key))
+	return C.GoBytes(kv.key, C.int(kv.key_size)), nil
 }
 
 // Search performs WT_CURSOR::search call.
 func (c *Cursor) Search(key []byte) error {
 	keyP := unsafe.Pointer(&key[0])
 	r := C.wt_cursor_search(c.c, keyP, C.size_t(len(key)))
-	return wtError(r)
+	return c.cursorError(r, "search", key)
+}
+
+// Compare performs WT_CURSOR::compare call. Returns value < 0 if `c` is positioned on a smaller
+// key than `other`, 0 if keys are equal and value > 0 otherwise. Both cursors must be positioned
+// and must be opened on the same data source.
+func (c *Cursor) Compare(other *Cursor) (int, error) {
+	var cmp C.int
+	if r := C.wt_cursor_compare(c.c, other.c, &cmp); r != 0 {
+		return 0, c.cursorError(r, "compare", nil)
+	}
+	return int(cmp), nil
+}
+
+// Equals performs WT_CURSOR::equals call. Returns true if both cursors are positioned
+// on the same key. Both cursors must be positioned and must be opened on the same
+// data source.
+func (c *Cursor) Equals(other *Cursor) (bool, error) {
+	var equal C.int
+	if r := C.wt_cursor_equals(c.c, other.c, &equal); r != 0 {
+		return false, c.cursorError(r, "equals", nil)
+	}
+	return equal != 0, nil
 }
 
 // NearMatchType describes type of match that is found with SearchNear call.
@@ -195,7 +498,7 @@ func (c *Cursor) SearchNear(key []byte) (NearMatchType, error) {
 	keyP := unsafe.Pointer(&key[0])
 	r := C.wt_cursor_search_near(c.c, keyP, C.size_t(len(key)), &exact)
 	if r != 0 {
-		return 0, wtError(r)
+		return 0, c.cursorError(r, "search_near", key)
 	}
 	if exact < 0 {
 		return MatchedSmaller, nil
@@ -232,7 +535,7 @@ func (c *Cursor) ReadValue(key []byte) ([]byte, error) {
 // using WT_CURSOR::remove call.
 func (c *Cursor) Remove() error {
 	r := C.wt_cursor_remove(c.c)
-	return wtError(r)
+	return c.cursorError(r, "remove", nil)
 }
 
 // Update updates value of element that cursor is pointing to
@@ -243,7 +546,7 @@ func (c *Cursor) Update(value []byte) error {
 		valueP = unsafe.Pointer(&value[0])
 	}
 	r := C.wt_cursor_update(c.c, valueP, C.size_t(len(value)))
-	return wtError(r)
+	return c.cursorError(r, "update", nil)
 }
 
 // Insert performs WT_CURSOR::insert call. Cursor is reset after this call.
@@ -255,14 +558,14 @@ func (c *Cursor) Insert(key, value []byte) error {
 	}
 	r := C.wt_cursor_insert(
 		c.c, keyP, C.size_t(len(key)), valueP, C.size_t(len(value)))
-	return wtError(r)
+	return c.cursorError(r, "insert", key)
 }
 
 // RemoveKey performs WT_CURSOR::remove call. Cursor is reset after this call.
 func (c *Cursor) RemoveKey(key []byte) error {
 	keyP := unsafe.Pointer(&key[0])
 	r := C.wt_cursor_remove_and_reset(c.c, keyP, C.size_t(len(key)))
-	return wtError(r)
+	return c.cursorError(r, "remove", key)
 }
 
 // UpdateValue performs WT_CURSOR::update call. Cursor is reset after this call.
@@ -274,7 +577,162 @@ func (c *Cursor) UpdateValue(key, value []byte) error {
 	}
 	r := C.wt_cursor_update_and_reset(
 		c.c, keyP, C.size_t(len(key)), valueP, C.size_t(len(value)))
-	return wtError(r)
+	return c.cursorError(r, "update", key)
+}
+
+// Reserve performs WT_CURSOR::reserve call. It claims the record for the current
+// transaction without changing it, thus conflicting writes from other transactions
+// fail with ErrRollback, until this transaction finishes. Doesn't generate any log
+// records. Must be called inside a transaction, otherwise ErrTxRequired is returned.
+// Cursor is reset after this call.
+func (c *Cursor) Reserve(key []byte) error {
+	if !c.s.InTx() {
+		return ErrTxRequired
+	}
+	keyP := unsafe.Pointer(&key[0])
+	r := C.wt_cursor_reserve_and_reset(c.c, keyP, C.size_t(len(key)))
+	return c.cursorError(r, "reserve", key)
+}
+
+// Batch accumulates key/value pairs in a single contiguous buffer, so that they
+// can all be inserted with a single CGO call using Cursor.InsertBatch. Batch can be
+// reused for multiple inserts by calling Reset.
+type Batch struct {
+	buf   []byte
+	sizes []C.size_t // Sizes of keys and values interleaved: k0, v0, k1, v1, ...
+}
+
+// Add appends key/value pair to the batch. Both key and value are copied,
+// thus they can be reused by the caller right away.
+func (b *Batch) Add(key, value []byte) {
+	b.buf = append(b.buf, key...)
+	b.buf = append(b.buf, value...)
+	b.sizes = append(b.sizes, C.size_t(len(key)), C.size_t(len(value)))
+}
+
+// Len returns number of key/value pairs in the batch.
+func (b *Batch) Len() int {
+	return len(b.sizes) / 2
+}
+
+// Reset clears the batch, while keeping already allocated memory around.
+func (b *Batch) Reset() {
+	b.buf = b.buf[:0]
+	b.sizes = b.sizes[:0]
+}
+
+// InsertBatch performs WT_CURSOR::insert call for every key/value pair in the batch,
+// in order, using a single CGO call. Returns number of pairs that were inserted. If
+// error is returned, returned count is the index of the pair that failed to insert.
+// Cursor is reset after this call.
+func (c *Cursor) InsertBatch(b *Batch) (int, error) {
+	n := b.Len()
+	if n == 0 {
+		return 0, nil
+	}
+	var dataP unsafe.Pointer
+	if len(b.buf) > 0 {
+		dataP = unsafe.Pointer(&b.buf[0])
+	}
+	var inserted C.size_t
+	r := C.wt_cursor_insert_batch(
+		c.c, (*C.uint8_t)(dataP), &b.sizes[0], C.size_t(n), &inserted)
+	return int(inserted), c.cursorError(r, "insert", nil)
+}
+
+// ErrBatchBufferTooSmall is returned by NextBatch and PrevBatch calls, when buffer
+// is too small to fit even a single key/value pair.
+var ErrBatchBufferTooSmall = errors.New("buffer too small for next key/value pair")
+
+// NextBatch performs WT_CURSOR::next calls until either `max` key/value pairs are read,
+// `buf` has no space left for the next pair, or the end is reached, using a single CGO call.
+// If `max` is <= 0, number of pairs is only limited by the size of `buf`. Key/value pairs
+// are copied into `buf`, thus returned KVBatch is only valid until `buf` is reused.
+//
+// When cursor reaches the end, pairs that were read before it are returned together with
+// ErrNotFound error. Similar to io.Reader, caller must process returned pairs before
+// handling the error.
+func (c *Cursor) NextBatch(buf []byte, max int) (KVBatch, error) {
+	return c.readBatch(0, buf, max)
+}
+
+// PrevBatch is same as NextBatch, except it moves backwards using WT_CURSOR::prev calls.
+func (c *Cursor) PrevBatch(buf []byte, max int) (KVBatch, error) {
+	return c.readBatch(1, buf, max)
+}
+
+func (c *Cursor) readBatch(reverse C.int, buf []byte, max int) (KVBatch, error) {
+	if len(buf) == 0 {
+		return KVBatch{}, ErrBatchBufferTooSmall
+	}
+	if max <= 0 {
+		max = len(buf)
+	}
+	var count, used C.size_t
+	var full C.int
+	r := C.wt_cursor_read_batch(
+		c.c, reverse,
+		(*C.uint8_t)(unsafe.Pointer(&buf[0])), C.size_t(len(buf)), C.size_t(max),
+		&count, &used, &full)
+	if r == 0 && count == 0 && full != 0 {
+		return KVBatch{}, ErrBatchBufferTooSmall
+	}
+	return KVBatch{buf: buf[:used], n: int(count)}, c.cursorError(r, moveOp(reverse), nil)
+}
+
+// KVBatch is a set of key/value pairs read by NextBatch or PrevBatch calls. Iterating over
+// KVBatch doesn't allocate any memory:
+//
+//	for batch.Next() {
+//		k, v := batch.Key(), batch.Value()
+//		...
+//	}
+type KVBatch struct {
+	buf   []byte
+	n     int
+	off   int
+	key   []byte
+	value []byte
+}
+
+// Len returns total number of key/value pairs in the batch.
+func (b *KVBatch) Len() int {
+	return b.n
+}
+
+// Next moves to the next key/value pair in the batch. Returns false when there are
+// no more pairs left.
+func (b *KVBatch) Next() bool {
+	if b.off >= len(b.buf) {
+		b.key, b.value = nil, nil
+		return false
+	}
+	keySize := int(binary.LittleEndian.Uint32(b.buf[b.off:]))
+	valueSize := int(binary.LittleEndian.Uint32(b.buf[b.off+4:]))
+	b.off += 8
+	b.key = b.buf[b.off : b.off+keySize : b.off+keySize]
+	b.off += keySize
+	b.value = b.buf[b.off : b.off+valueSize : b.off+valueSize]
+	b.off += valueSize
+	return true
+}
+
+// Key returns key of the current pair. Returned slice points into the batch buffer.
+func (b *KVBatch) Key() []byte {
+	return b.key
+}
+
+// Value returns value of the current pair. Returned slice points into the batch buffer.
+func (b *KVBatch) Value() []byte {
+	return b.value
+}
+
+// appendC appends `size` bytes of `C` memory to `dst`.
+func appendC(dst []byte, data unsafe.Pointer, size C.size_t) []byte {
+	if size == 0 {
+		return dst
+	}
+	return append(dst, (*[goArrayMaxLen]byte)(data)[:size:size]...)
 }
 
 func copyBuffer(in []byte) []byte {
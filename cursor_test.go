@@ -1,21 +1,21 @@
-package wt
+package wt_test
 
 import (
 	"encoding/binary"
-	"io/ioutil"
-	"os"
 	"runtime"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
 )
 
 // BenchmarkCursorInsert-4 - 564543 - 2357 ns/op - 1.00 cgocalls/op - 0 B/op - 0 allocs/op
 // This benchmark mainly exists to confirm that Insert call doesn't do any
 // memory allocations.
 func BenchmarkCursorInsert(b *testing.B) {
-	s := setupDbForBench(b)
+	s := wttest.NewDB(b).Session
 	c, err := s.OpenCursor("table:test_table")
 	require.NoError(b, err)
 	b.Cleanup(func() { c.Close() })
@@ -37,9 +37,87 @@ func BenchmarkCursorInsert(b *testing.B) {
 	b.ReportMetric(float64(runtime.NumCgoCall()-cgoCalls0)/float64(b.N), "cgocalls/op")
 }
 
+// BenchmarkCursorInsertBatch inserts items in batches of 100, using single CGO call
+// per batch. All metrics are reported per inserted item to be directly comparable
+// with BenchmarkCursorInsert.
+func BenchmarkCursorInsertBatch(b *testing.B) {
+	s := wttest.NewDB(b).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(b, err)
+	b.Cleanup(func() { c.Close() })
+
+	const batchSize = 100
+	insertK := []byte("testkeyXXXXXXXX")
+	insertV := []byte("testvalXXXXXXXX")
+	batch := &wt.Batch{}
+
+	cgoCalls0 := runtime.NumCgoCall()
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		binary.LittleEndian.PutUint64(insertK[len(insertK)-8:], uint64(i))
+		binary.LittleEndian.PutUint64(insertV[len(insertV)-8:], uint64(i))
+		batch.Add(insertK, insertV)
+		if batch.Len() < batchSize && i < b.N-1 {
+			continue
+		}
+		_, err = c.InsertBatch(batch)
+		if err != nil {
+			b.Fatal(err)
+		}
+		batch.Reset()
+	}
+	b.ReportMetric(float64(runtime.NumCgoCall()-cgoCalls0)/float64(b.N), "cgocalls/op")
+}
+
+func TestCursorInsertBatch(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table", wt.CursorCfg{Overwrite: wt.False})
+	require.NoError(t, err)
+	defer c.Close()
+
+	batch := &wt.Batch{}
+	n, err := c.InsertBatch(batch)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, n)
+
+	batch.Add([]byte("testkey1"), []byte("testvalue1"))
+	batch.Add([]byte("testkey2"), nil)
+	batch.Add([]byte("testkey3"), []byte("testvalue3"))
+	require.EqualValues(t, 3, batch.Len())
+	n, err = c.InsertBatch(batch)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	v, err := c.ReadValue([]byte("testkey1"))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte("testvalue1"), v)
+	v, err = c.ReadValue([]byte("testkey2"))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte(nil), v)
+	v, err = c.ReadValue([]byte("testkey3"))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte("testvalue3"), v)
+
+	// Insert must stop on first failure and report its index.
+	batch.Reset()
+	batch.Add([]byte("testkey4"), []byte("testvalue4"))
+	batch.Add([]byte("testkey2"), []byte("testvalue2"))
+	batch.Add([]byte("testkey5"), []byte("testvalue5"))
+	n, err = c.InsertBatch(batch)
+	require.Error(t, err)
+	require.EqualValues(t, wt.ErrDuplicateKey, wt.ErrCode(err))
+	require.EqualValues(t, 1, n)
+
+	_, err = c.ReadValue([]byte("testkey4"))
+	require.NoError(t, err)
+	_, err = c.ReadValue([]byte("testkey5"))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+}
+
 // BenchmarkCursorScan-4 - 1585243 - 722 ns/op - 3.00 cgocalls/op - 96 B/op - 2 allocs/op
 func BenchmarkCursorScan(b *testing.B) {
-	s := setupDbForBench(b)
+	s := wttest.NewDB(b).Session
 	c, err := s.OpenCursor("table:test_table")
 	require.NoError(b, err)
 	b.Cleanup(func() { c.Close() })
@@ -82,19 +160,209 @@ func BenchmarkCursorScan(b *testing.B) {
 	b.ReportMetric(float64(runtime.NumCgoCall()-cgoCalls0)/float64(b.N), "cgocalls/op")
 }
 
-func setupDbForBench(b *testing.B) *Session {
-	dbDir, err := ioutil.TempDir("", "wt_")
+// BenchmarkCursorScanBatch reads same data as BenchmarkCursorScan, but using NextBatch
+// calls. All metrics are reported per scanned item.
+func BenchmarkCursorScanBatch(b *testing.B) {
+	s := wttest.NewDB(b).Session
+	c, err := s.OpenCursor("table:test_table")
 	require.NoError(b, err)
-	b.Cleanup(func() { os.RemoveAll(dbDir) })
+	b.Cleanup(func() { c.Close() })
+	maxItems := b.N
+	if maxItems > 10000 {
+		maxItems = 10000
+	}
+	for i := 0; i < maxItems; i++ {
+		err := c.Insert(
+			[]byte("testkey"+strconv.Itoa(i)),
+			[]byte("testval"+strconv.Itoa(i)))
+		require.NoError(b, err)
+	}
 
-	c, err := Open(dbDir, ConnCfg{Create: True})
+	err = c.Reset()
 	require.NoError(b, err)
-	b.Cleanup(func() { require.NoError(b, c.Close()) })
+	buf := make([]byte, 64*1024)
+	cgoCalls0 := runtime.NumCgoCall()
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; {
+		batch, err := c.NextBatch(buf, b.N-i)
+		for batch.Next() {
+			_, _ = batch.Key(), batch.Value()
+		}
+		i += batch.Len()
+		if wt.ErrCode(err) == wt.ErrNotFound {
+			continue // Cursor is reset, start reading from the start again.
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(runtime.NumCgoCall()-cgoCalls0)/float64(b.N), "cgocalls/op")
+}
 
-	s, err := c.OpenSession()
+func TestCursorReadBatch(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < 10; i++ {
+		err := c.Insert([]byte("testkey"+strconv.Itoa(i)), []byte("testval"+strconv.Itoa(i)))
+		require.NoError(t, err)
+	}
+
+	_, err = c.NextBatch(make([]byte, 10), 0)
+	require.EqualValues(t, wt.ErrBatchBufferTooSmall, err)
+
+	// Each pair takes 8+8+8 = 24 bytes, thus buffer can fit exactly 4 pairs.
+	buf := make([]byte, 100)
+	var keys []string
+	for {
+		batch, err := c.NextBatch(buf, 0)
+		require.LessOrEqual(t, batch.Len(), 4)
+		for batch.Next() {
+			require.EqualValues(t, "testval", string(batch.Value()[:7]))
+			keys = append(keys, string(batch.Key()))
+		}
+		if wt.ErrCode(err) == wt.ErrNotFound {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.Len(t, keys, 10)
+	for i, k := range keys {
+		require.EqualValues(t, "testkey"+strconv.Itoa(i), k)
+	}
+
+	batch, err := c.PrevBatch(buf, 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, batch.Len())
+	for i := 9; batch.Next(); i-- {
+		require.EqualValues(t, "testkey"+strconv.Itoa(i), string(batch.Key()))
+	}
+	batch, err = c.PrevBatch(buf, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, batch.Len())
+	for i := 6; batch.Next(); i-- {
+		require.EqualValues(t, "testkey"+strconv.Itoa(i), string(batch.Key()))
+	}
+}
+
+// BenchmarkCursorScanInto reads same data as BenchmarkCursorScan, but using NextInto
+// call with reusable buffers. This benchmark mainly exists to confirm that NextInto
+// does a single CGO call and no memory allocations.
+func BenchmarkCursorScanInto(b *testing.B) {
+	s := wttest.NewDB(b).Session
+	c, err := s.OpenCursor("table:test_table")
 	require.NoError(b, err)
-	b.Cleanup(func() { require.NoError(b, s.Close()) })
-	err = s.Create("table:test_table")
+	b.Cleanup(func() { c.Close() })
+	maxItems := b.N
+	if maxItems > 10000 {
+		maxItems = 10000
+	}
+	for i := 0; i < maxItems; i++ {
+		err := c.Insert(
+			[]byte("testkey"+strconv.Itoa(i)),
+			[]byte("testval"+strconv.Itoa(i)))
+		require.NoError(b, err)
+	}
+
+	err = c.Reset()
 	require.NoError(b, err)
-	return s
+	kbuf := make([]byte, 0, 64)
+	vbuf := make([]byte, 0, 64)
+	cgoCalls0 := runtime.NumCgoCall()
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		kbuf, vbuf, err = c.NextInto(kbuf[:0], vbuf[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+		if (i+1)%maxItems == 0 {
+			c.Reset() // Reset to start reading from the start again.
+		}
+	}
+	b.ReportMetric(float64(runtime.NumCgoCall()-cgoCalls0)/float64(b.N), "cgocalls/op")
+}
+
+func TestCursorReadInto(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+	err = c.Insert([]byte("testkey1"), []byte("testvalue1"))
+	require.NoError(t, err)
+	err = c.Insert([]byte("testkey2"), nil)
+	require.NoError(t, err)
+
+	err = c.Search([]byte("testkey1"))
+	require.NoError(t, err)
+	k, err := c.KeyInto([]byte("prefix:"))
+	require.NoError(t, err)
+	require.EqualValues(t, "prefix:testkey1", string(k))
+	v, err := c.ValueInto(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, "testvalue1", string(v))
+
+	k, v, err = c.NextInto(k[:0], v[:0])
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey2", string(k))
+	require.EqualValues(t, "", string(v))
+
+	_, _, err = c.NextInto(k[:0], v[:0])
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	k, v, err = c.PrevInto(k[:0], v[:0])
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey2", string(k))
+	k, v, err = c.PrevInto(k[:0], v[:0])
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey1", string(k))
+	require.EqualValues(t, "testvalue1", string(v))
+}
+
+func TestCursorDupCompare(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c1, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c1.Close()
+	for i := 0; i < 5; i++ {
+		err := c1.Insert([]byte("testkey"+strconv.Itoa(i)), []byte("testval"+strconv.Itoa(i)))
+		require.NoError(t, err)
+	}
+
+	err = c1.Search([]byte("testkey2"))
+	require.NoError(t, err)
+	c2, err := s.DupCursor(c1)
+	require.NoError(t, err)
+	defer c2.Close()
+
+	k, err := c2.Key()
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey2", string(k))
+	eq, err := c1.Equals(c2)
+	require.NoError(t, err)
+	require.True(t, eq)
+	cmp, err := c1.Compare(c2)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, cmp)
+
+	// Duplicated cursor moves independently from the original.
+	require.NoError(t, c2.Next())
+	k, err = c2.Key()
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey3", string(k))
+	eq, err = c1.Equals(c2)
+	require.NoError(t, err)
+	require.False(t, eq)
+	cmp, err = c1.Compare(c2)
+	require.NoError(t, err)
+	require.Less(t, cmp, 0)
+	cmp, err = c2.Compare(c1)
+	require.NoError(t, err)
+	require.Greater(t, cmp, 0)
+
+	require.NoError(t, c1.Reset())
+	_, err = c1.Compare(c2)
+	require.Error(t, err)
 }
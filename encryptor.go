@@ -0,0 +1,145 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// Encryptor is a Go implementation of WT_ENCRYPTOR for a single encryption key.
+// Methods can be called concurrently from multiple goroutines.
+type Encryptor interface {
+	// Encrypt encrypts `src` into `dst` and returns size of encrypted data. `dst` is
+	// always at least Overhead() bytes larger than `src`.
+	Encrypt(dst, src []byte) (int, error)
+	// Decrypt decrypts `src` into `dst` and returns size of decrypted data. `src` is
+	// exactly the data that was produced by Encrypt call.
+	Decrypt(dst, src []byte) (int, error)
+	// Overhead returns maximum number of bytes that encrypted data can be larger than
+	// its input.
+	Overhead() int
+}
+
+// KeyProvider maps key ids, configured with 'keyid' option, to encryption keys.
+type KeyProvider interface {
+	Key(keyID string) ([]byte, error)
+}
+
+// KeyProviderFunc is an adapter to use ordinary functions as KeyProvider.
+type KeyProviderFunc func(keyID string) ([]byte, error)
+
+// Key implements KeyProvider interface.
+func (f KeyProviderFunc) Key(keyID string) ([]byte, error) {
+	return f(keyID)
+}
+
+// EncryptorSpec describes Go encryptor that can be added to a connection.
+type EncryptorSpec struct {
+	// New creates Encryptor for an encryption key.
+	New func(key []byte) (Encryptor, error)
+	// Keys provides encryption keys for key ids. If 'secretkey' option is configured
+	// instead of 'keyid', it is used as the encryption key directly and Keys can be nil.
+	Keys KeyProvider
+}
+
+// ErrNoEncryptionKey is returned when encryptor is configured without 'keyid' or
+// 'secretkey' options.
+var ErrNoEncryptionKey = errors.New("encryption key not configured")
+
+// AddEncryptor performs WT_CONNECTION::add_encryptor call, registering Go implementation
+// of an encryptor under `name`. Encryptor is released when connection is closed.
+//
+// Connection level encryption, i.e. ConnCfg{Encryption: "name=<name>,keyid=<keyid>"}, encrypts
+// the log and the metadata and it needs encryptor to be added during wiredtiger_open call, thus
+// such encryptors must be passed in with ConnCfg.Encryptors config instead. Data sources are
+// encrypted with DataSourceCfg{Encryption: "name=<name>,keyid=<keyid>"} config. Opening
+// connection or data source fails, if encryptor isn't added or if key for keyid can't be
+// provided.
+func (c *Connection) AddEncryptor(name string, spec EncryptorSpec) error {
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+	h := cgo.NewHandle(spec)
+	r := C.wt_conn_add_encryptor(c.c, nameC, C.uintptr_t(h))
+	if r != 0 {
+		h.Delete()
+	}
+	return c.connError(r, "add_encryptor", name)
+}
+
+//export goEncryptorCustomize
+func goEncryptorCustomize(
+	handle C.uintptr_t,
+	keyID unsafe.Pointer, keyIDLen C.size_t,
+	secretKey unsafe.Pointer, secretKeyLen C.size_t,
+	customHandle *C.uintptr_t) C.int {
+	spec := cgo.Handle(handle).Value().(EncryptorSpec)
+	var key []byte
+	if secretKeyLen > 0 {
+		key = append(key, bytesFromC(secretKey, secretKeyLen)...)
+	} else if keyIDLen > 0 && spec.Keys != nil {
+		var err error
+		key, err = spec.Keys.Key(string(bytesFromC(keyID, keyIDLen)))
+		if err != nil {
+			return errorCodeC(err)
+		}
+	} else {
+		return errorCodeC(ErrNoEncryptionKey)
+	}
+	encryptor, err := spec.New(key)
+	if err != nil {
+		return errorCodeC(err)
+	}
+	*customHandle = C.uintptr_t(cgo.NewHandle(encryptor))
+	return 0
+}
+
+//export goEncryptorEncrypt
+func goEncryptorEncrypt(
+	handle C.uintptr_t,
+	src unsafe.Pointer, srcLen C.size_t,
+	dst unsafe.Pointer, dstLen C.size_t,
+	resultLen *C.size_t) C.int {
+	encryptor, ok := cgo.Handle(handle).Value().(Encryptor)
+	if !ok {
+		return errorCodeC(ErrNoEncryptionKey)
+	}
+	n, err := encryptor.Encrypt(bytesFromC(dst, dstLen), bytesFromC(src, srcLen))
+	if err != nil {
+		return errorCodeC(err)
+	}
+	*resultLen = C.size_t(n)
+	return 0
+}
+
+//export goEncryptorDecrypt
+func goEncryptorDecrypt(
+	handle C.uintptr_t,
+	src unsafe.Pointer, srcLen C.size_t,
+	dst unsafe.Pointer, dstLen C.size_t,
+	resultLen *C.size_t) C.int {
+	encryptor, ok := cgo.Handle(handle).Value().(Encryptor)
+	if !ok {
+		return errorCodeC(ErrNoEncryptionKey)
+	}
+	n, err := encryptor.Decrypt(bytesFromC(dst, dstLen), bytesFromC(src, srcLen))
+	if err != nil {
+		return errorCodeC(err)
+	}
+	*resultLen = C.size_t(n)
+	return 0
+}
+
+//export goEncryptorSizing
+func goEncryptorSizing(handle C.uintptr_t, expansionConstant *C.size_t) C.int {
+	*expansionConstant = 0
+	if encryptor, ok := cgo.Handle(handle).Value().(Encryptor); ok {
+		*expansionConstant = C.size_t(encryptor.Overhead())
+	}
+	return 0
+}
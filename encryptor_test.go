@@ -0,0 +1,85 @@
+package wt
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt/encryptors/aesgcm"
+)
+
+func TestEncryptor(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "wt_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dbDir)
+
+	keys := map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}
+	spec := EncryptorSpec{
+		New: func(key []byte) (Encryptor, error) { return aesgcm.New(key) },
+		Keys: KeyProviderFunc(func(keyID string) ([]byte, error) {
+			key, ok := keys[keyID]
+			if !ok {
+				return nil, fmt.Errorf("unknown key: %s", keyID)
+			}
+			return key, nil
+		}),
+	}
+	encryption := "name=" + aesgcm.Name + ",keyid=k1"
+	cfg := ConnCfg{
+		Create:     True,
+		Encryption: encryption,
+		Log:        "enabled",
+		Encryptors: map[string]EncryptorSpec{aesgcm.Name: spec},
+	}
+	c, err := Open(dbDir, cfg)
+	require.NoError(t, err)
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+	err = s.Create("table:secret", DataSourceCfg{Encryption: encryption})
+	require.NoError(t, err)
+	c1, err := s.OpenCursor("table:secret")
+	require.NoError(t, err)
+	marker := []byte("plaintext-marker")
+	for i := 0; i < 1000; i++ {
+		err := c1.Insert([]byte("key"+strconv.Itoa(i)), marker)
+		require.NoError(t, err)
+	}
+	require.NoError(t, c1.Close())
+	require.NoError(t, s.Close())
+	require.NoError(t, c.Close())
+
+	// Neither data files nor the log should contain any plaintext.
+	files, err := filepath.Glob(filepath.Join(dbDir, "*"))
+	require.NoError(t, err)
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(data, marker), f)
+	}
+
+	// Reopen must fail without the encryptor or without the key.
+	_, err = Open(dbDir, ConnCfg{Encryption: encryption})
+	require.Error(t, err)
+	delete(keys, "k1")
+	_, err = Open(dbDir, cfg)
+	require.Error(t, err)
+
+	keys["k1"] = bytes.Repeat([]byte{1}, 32)
+	c, err = Open(dbDir, cfg)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, c.Close()) }()
+	s, err = c.OpenSession()
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	c1, err = s.OpenCursor("table:secret")
+	require.NoError(t, err)
+	defer c1.Close()
+	v, err := c1.ReadValue([]byte("key999"))
+	require.NoError(t, err)
+	require.EqualValues(t, marker, v)
+}
@@ -0,0 +1,70 @@
+// Package aesgcm implements wt.Encryptor using AES in Galois/Counter Mode. Each encrypted
+// block is prefixed with a random 96-bit nonce, thus a single key shouldn't be used for
+// more than 2^32 encryptions.
+package aesgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Name is the conventional name to register encryptor with.
+const Name = "aesgcm"
+
+// Encryptor implements wt.Encryptor interface.
+type Encryptor struct {
+	aead cipher.AEAD
+}
+
+// New creates new Encryptor. Key must be 16, 24 or 32 bytes long, to select AES-128,
+// AES-192 or AES-256.
+func New(key []byte) (*Encryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encryptor{aead: aead}, nil
+}
+
+var errShortBuffer = errors.New("aesgcm: buffer too small")
+
+// Encrypt implements wt.Encryptor interface.
+func (e *Encryptor) Encrypt(dst, src []byte) (int, error) {
+	if len(dst) < len(src)+e.Overhead() {
+		return 0, errShortBuffer
+	}
+	nonce := dst[:e.aead.NonceSize()]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return 0, err
+	}
+	out := e.aead.Seal(dst[len(nonce):len(nonce)], nonce, src, nil)
+	return len(nonce) + len(out), nil
+}
+
+// Decrypt implements wt.Encryptor interface.
+func (e *Encryptor) Decrypt(dst, src []byte) (int, error) {
+	nonceSize := e.aead.NonceSize()
+	if len(src) < nonceSize+e.aead.Overhead() {
+		return 0, errShortBuffer
+	}
+	if len(dst) < len(src)-e.Overhead() {
+		return 0, errShortBuffer
+	}
+	out, err := e.aead.Open(dst[:0], src[:nonceSize], src[nonceSize:], nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(out), nil
+}
+
+// Overhead implements wt.Encryptor interface.
+func (e *Encryptor) Overhead() int {
+	return e.aead.NonceSize() + e.aead.Overhead()
+}
@@ -0,0 +1,37 @@
+package aesgcm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptor(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	e, err := New(key)
+	require.NoError(t, err)
+
+	src := []byte("secret data")
+	dst := make([]byte, len(src)+e.Overhead())
+	n, err := e.Encrypt(dst, src)
+	require.NoError(t, err)
+	require.EqualValues(t, len(dst), n)
+	require.False(t, bytes.Contains(dst, src))
+
+	out := make([]byte, len(src))
+	m, err := e.Decrypt(out, dst[:n])
+	require.NoError(t, err)
+	require.EqualValues(t, src, out[:m])
+
+	// Decryption with a different key must fail.
+	e2, err := New(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = e2.Decrypt(out, dst[:n])
+	require.Error(t, err)
+
+	_, err = e.Encrypt(make([]byte, len(src)), src)
+	require.Error(t, err)
+	_, err = New([]byte("short"))
+	require.Error(t, err)
+}
@@ -1,15 +1,29 @@
 package wt
 
 /*
+#include <errno.h>
 #include <stdlib.h>
-#include <wiredtiger.h>
+#include "callbacks.h"
 */
 import "C"
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
 // ErrorCode enum describes WiredTiger-specific error codes.
 type ErrorCode int
 
 // WiredTiger specific error codes.
+//
+// ErrCacheAll (WT_CACHE_FULL) is returned when operation can't proceed because cache is
+// full. It can only happen for in-memory databases, since they can't evict data to disk,
+// or when cache is full of data that is pinned by long running transactions. Transaction
+// that gets ErrCacheAll must be rolled back, and operation can be retried once space is
+// freed up, for example by removing data. Session.CacheStats call can be used to monitor
+// how close cache is to becoming full.
 const (
 	ErrRollback        ErrorCode = C.WT_ROLLBACK
 	ErrDuplicateKey    ErrorCode = C.WT_DUPLICATE_KEY
@@ -22,28 +36,135 @@ const (
 	ErrTrySalvage      ErrorCode = C.WT_TRY_SALVAGE
 )
 
-// Error describes WiredTiger error.
+// ErrTimedOut (ETIMEDOUT) is returned by Session.TransactionSync call, when log records
+// don't become durable in time.
+const ErrTimedOut ErrorCode = C.ETIMEDOUT
+
+// ErrTxRequired is returned by operations that must be called inside a transaction,
+// when session has no active transaction.
+var ErrTxRequired = errors.New("operation requires an active transaction")
+
+// Error implements error interface, so that error codes can be used as targets for
+// errors.Is calls, i.e. errors.Is(err, ErrNotFound).
+func (c ErrorCode) Error() string {
+	return C.GoString(C.wiredtiger_strerror(C.int(c)))
+}
+
+// ErrorKeyMaxLen is the maximum number of key bytes that are included in errors. Longer
+// keys are truncated. Zero excludes keys from errors altogether.
+var ErrorKeyMaxLen = 64
+
+// Error describes WiredTiger error, together with the context of the operation that
+// has failed. ErrNotFound errors are part of the normal control flow, thus for performance
+// reasons they only have Code and Op set.
 type Error struct {
 	Code ErrorCode
+	// Op is the name of the failed operation, i.e. name of WT_CONNECTION, WT_SESSION or
+	// WT_CURSOR method, such as "search" or "create", or "wiredtiger_open".
+	Op string
+	// URI of the data source, if operation has one. For connection operations, it is the
+	// home directory, the config or the name of the extension instead.
+	URI string
+	// Key that operation was performed with, truncated to ErrorKeyMaxLen bytes.
+	Key []byte
+	// Message is the detailed error message that WiredTiger has reported to the session
+	// or the connection event handler, if any.
+	Message string
+
+	keyTruncated bool
 }
 
 func (e *Error) Error() string {
-	return C.GoString(C.wiredtiger_strerror(C.int(e.Code)))
+	var b strings.Builder
+	if e.Op != "" {
+		b.WriteString(e.Op)
+		if e.URI != "" {
+			b.WriteString(" " + e.URI)
+		}
+		if e.Key != nil {
+			b.WriteString(" key=" + strconv.Quote(string(e.Key)))
+			if e.keyTruncated {
+				b.WriteString("...")
+			}
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Code.Error())
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+	return b.String()
 }
 
-// ErrCode extracts WiredTiger error code from any error. If input is not
-// WiredTiger error, it will return ErrError.
+// Is reports whether `target` is an ErrorCode or an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.Code == t
+	case *Error:
+		return e.Code == t.Code
+	}
+	return false
+}
+
+// ErrCode extracts WiredTiger error code from any error, including errors that wrap
+// WiredTiger errors. If input is not WiredTiger error, it will return ErrError.
 func ErrCode(e error) ErrorCode {
-	wtErr, ok := e.(*Error)
-	if !ok {
-		return ErrError
+	var wtErr *Error
+	if errors.As(e, &wtErr) {
+		return wtErr.Code
+	}
+	var code ErrorCode
+	if errors.As(e, &code) {
+		return code
 	}
-	return wtErr.Code
+	return ErrError
 }
 
-func wtError(errorCode C.int) error {
+// connError returns error for a failed connection operation. `uri` is the home
+// directory, the config or the name of the extension that operation was performed with.
+func (c *Connection) connError(errorCode C.int, op, uri string) error {
 	if errorCode == 0 {
 		return nil
 	}
-	return &Error{Code: ErrorCode(errorCode)}
+	return &Error{Code: ErrorCode(errorCode), Op: op, URI: uri, Message: c.lastErrorMessage(errorCode)}
+}
+
+// sessionError returns error for a failed session operation.
+func (s *Session) sessionError(errorCode C.int, op, uri string) error {
+	if errorCode == 0 {
+		return nil
+	}
+	e := &Error{Code: ErrorCode(errorCode), Op: op}
+	msg := s.lastErrorMessage(errorCode)
+	if e.Code != ErrNotFound {
+		e.URI = uri
+		e.Message = msg
+	}
+	return e
+}
+
+// cursorError returns error for a failed cursor operation. `key` is nil, if operation
+// doesn't have a key.
+func (c *Cursor) cursorError(errorCode C.int, op string, key []byte) error {
+	if errorCode == 0 {
+		return nil
+	}
+	e := &Error{Code: ErrorCode(errorCode), Op: op}
+	msg := c.s.lastErrorMessage(errorCode)
+	if e.Code == ErrNotFound {
+		return e
+	}
+	if c.c != nil {
+		e.URI = C.GoString(c.c.uri)
+	}
+	if key != nil && ErrorKeyMaxLen > 0 {
+		if len(key) > ErrorKeyMaxLen {
+			key = key[:ErrorKeyMaxLen]
+			e.keyTruncated = true
+		}
+		e.Key = append([]byte{}, key...)
+	}
+	e.Message = msg
+	return e
 }
@@ -0,0 +1,81 @@
+package wt_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestErrorIs(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+
+	err = c.Search([]byte("missing"))
+	require.True(t, errors.Is(err, wt.ErrNotFound))
+	require.False(t, errors.Is(err, wt.ErrDuplicateKey))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	wrapped := fmt.Errorf("reading config: %w", err)
+	require.True(t, errors.Is(wrapped, wt.ErrNotFound))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(wrapped))
+	require.True(t, errors.Is(wrapped, &wt.Error{Code: wt.ErrNotFound}))
+
+	require.EqualValues(t, wt.ErrRollback, wt.ErrCode(fmt.Errorf("tx: %w", wt.ErrRollback)))
+	require.EqualValues(t, wt.ErrError, wt.ErrCode(errors.New("not a WiredTiger error")))
+}
+
+func TestErrorContext(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table", wt.CursorCfg{Overwrite: wt.False})
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Insert([]byte("key1"), []byte("value1")))
+	err = c.Insert([]byte("key1"), []byte("value2"))
+	require.True(t, errors.Is(err, wt.ErrDuplicateKey))
+	var wtErr *wt.Error
+	require.True(t, errors.As(err, &wtErr))
+	require.Equal(t, "insert", wtErr.Op)
+	require.Equal(t, "table:test_table", wtErr.URI)
+	require.EqualValues(t, "key1", wtErr.Key)
+	require.Contains(t, err.Error(), `insert table:test_table key="key1"`)
+
+	longKey := []byte(strings.Repeat("k", wt.ErrorKeyMaxLen*2))
+	require.NoError(t, c.Insert(longKey, []byte("value1")))
+	err = c.Insert(longKey, []byte("value2"))
+	require.True(t, errors.As(err, &wtErr))
+	require.Len(t, wtErr.Key, wt.ErrorKeyMaxLen)
+	require.Contains(t, err.Error(), `"...: `)
+
+	err = s.Create("table:bad_table", wt.DataSourceCfg{KeyFormat: "invalid"})
+	require.Error(t, err)
+	require.True(t, errors.As(err, &wtErr))
+	require.Equal(t, "create", wtErr.Op)
+	require.Equal(t, "table:bad_table", wtErr.URI)
+	require.NotEmpty(t, wtErr.Message)
+	require.Contains(t, err.Error(), wtErr.Message)
+}
+
+func TestConnectionErrorContext(t *testing.T) {
+	dbDir := t.TempDir()
+	_, err := wt.Open(dbDir)
+	var wtErr *wt.Error
+	require.True(t, errors.As(err, &wtErr))
+	require.Equal(t, "wiredtiger_open", wtErr.Op)
+	require.Equal(t, dbDir, wtErr.URI)
+	require.NotEmpty(t, wtErr.Message)
+
+	db := wttest.NewDB(t)
+	_, err = db.Conn.OpenSession(wt.SessionCfg{Isolation: "invalid"})
+	require.True(t, errors.As(err, &wtErr))
+	require.Equal(t, "open_session", wtErr.Op)
+	require.Contains(t, wtErr.URI, "isolation=invalid")
+	require.NotEmpty(t, wtErr.Message)
+}
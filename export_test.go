@@ -0,0 +1,17 @@
+package wt
+
+// Internals that are used by tests in wt_test package.
+
+var PrefixUpperBound = prefixUpperBound
+
+// SetRangeUseBound toggles use of WT_CURSOR::bound by Cursor.Range, and returns function
+// that restores previous setting.
+func SetRangeUseBound(v bool) (restore func()) {
+	prev := rangeUseBound
+	rangeUseBound = v
+	return func() { rangeUseBound = prev }
+}
+
+func (c *Connection) ScratchDir() string { return c.scratchDir }
+
+func (c *Connection) SessionErrors() int { return len(c.sessionErrors) }
@@ -0,0 +1,98 @@
+package wt
+
+/*
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// Extensions that are part of ConnCfg need to be added during wiredtiger_open call. To achieve
+// that, Open loads 'local' extension, i.e. extension that is linked into the executable itself,
+// with 'wt_go_extension_init' entry point. Entry point adds extensions from ConnCfg that is
+// passed to the pending Open call. Extension is loaded early, since file system must be set
+// before WiredTiger accesses any files.
+const extensionsConfig = "extensions=[local=(entry=wt_go_extension_init,early_load=true)]"
+
+var (
+	extensionsMu      sync.Mutex
+	extensionsPending *ConnCfg
+)
+
+// hasExtensions returns true if `cfg` has any Go extensions that need to be added
+// during wiredtiger_open call.
+func hasExtensions(cfg ConnCfg) bool {
+	return len(cfg.Compressors) > 0 || len(cfg.Encryptors) > 0 || cfg.FileSystem != nil
+}
+
+// pendExtensions makes extensions from `cfg` available for 'wt_go_extension_init' entry
+// point. Returned function must be called once wiredtiger_open call completes.
+func pendExtensions(cfg ConnCfg) (unlock func()) {
+	extensionsMu.Lock()
+	extensionsPending = &cfg
+	return func() {
+		extensionsPending = nil
+		extensionsMu.Unlock()
+	}
+}
+
+// appendExtensionsConfig adds 'extensions' config to the NULL terminated configuration
+// string, produced by configC call.
+func appendExtensionsConfig(config string) string {
+	config = config[:len(config)-1]
+	if config != "" {
+		config += ","
+	}
+	return config + extensionsConfig + "\x00"
+}
+
+//export goExtensionInit
+func goExtensionInit(connection *C.WT_CONNECTION) C.int {
+	cfg := extensionsPending
+	if cfg == nil {
+		return C.int(ErrError)
+	}
+	c := &Connection{c: connection}
+	if cfg.FileSystem != nil {
+		if err := c.setFileSystem(cfg.FileSystem); err != nil {
+			return errorCodeC(err)
+		}
+	}
+	for name, compressor := range cfg.Compressors {
+		if err := c.AddCompressor(name, compressor); err != nil {
+			return errorCodeC(err)
+		}
+	}
+	for name, spec := range cfg.Encryptors {
+		if err := c.AddEncryptor(name, spec); err != nil {
+			return errorCodeC(err)
+		}
+	}
+	return 0
+}
+
+//export goHandleDelete
+func goHandleDelete(handle C.uintptr_t) {
+	cgo.Handle(handle).Delete()
+}
+
+// bytesFromC returns byte slice that points to `C` memory, without copying it.
+func bytesFromC(data unsafe.Pointer, size C.size_t) []byte {
+	if size == 0 {
+		return nil
+	}
+	return (*[goArrayMaxLen]byte)(data)[:size:size]
+}
+
+// errorCodeC converts Go error, returned by Go implementation of a WiredTiger extension,
+// to WiredTiger error code.
+func errorCodeC(err error) C.int {
+	if err == nil {
+		return 0
+	}
+	return C.int(ErrCode(err))
+}
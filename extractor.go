@@ -0,0 +1,78 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// Extractor is a custom index extractor, that can be registered with Connection.AddExtractor
+// call. Index that uses extractor must be created with 'extractor=name' and 'key_format=u'
+// configs, i.e. DataSourceCfg{Extractor: name, KeyFormat: "u"}.
+type Extractor interface {
+	// Extract calls `emit` for each index key that should be created for a record. `key` and
+	// `value` are raw, packed key and value of the record. `key`, `value` and `emit` are only
+	// valid during the call. Extract can be called concurrently from multiple goroutines.
+	Extract(key, value []byte, emit func(indexKey []byte)) error
+}
+
+// AddExtractor performs WT_CONNECTION::add_extractor call, registering Go implementation
+// of an index extractor under `name`. Extractor is released when connection is closed.
+func (c *Connection) AddExtractor(name string, e Extractor) error {
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+	h := cgo.NewHandle(e)
+	r := C.wt_conn_add_extractor(c.c, nameC, C.uintptr_t(h))
+	if r != 0 {
+		h.Delete()
+	}
+	return c.connError(r, "add_extractor", name)
+}
+
+// extractEmitter implements `emit` callback for extractors. Emitters are pooled, together
+// with their `emit` closures, to avoid allocations for each extracted record.
+type extractEmitter struct {
+	cursor *C.WT_CURSOR
+	r      C.int
+	emit   func(indexKey []byte)
+}
+
+var extractEmitters = sync.Pool{New: func() interface{} {
+	e := &extractEmitter{}
+	e.emit = func(indexKey []byte) {
+		if e.r != 0 {
+			return
+		}
+		var keyP unsafe.Pointer
+		if len(indexKey) > 0 {
+			keyP = unsafe.Pointer(&indexKey[0])
+		}
+		e.r = C.wt_extractor_emit(e.cursor, keyP, C.size_t(len(indexKey)))
+	}
+	return e
+}}
+
+//export goExtractorExtract
+func goExtractorExtract(
+	handle C.uintptr_t,
+	key unsafe.Pointer, keySize C.size_t,
+	value unsafe.Pointer, valueSize C.size_t,
+	resultCursor *C.WT_CURSOR) C.int {
+	e := cgo.Handle(handle).Value().(Extractor)
+	emitter := extractEmitters.Get().(*extractEmitter)
+	emitter.cursor, emitter.r = resultCursor, 0
+	err := e.Extract(bytesFromC(key, keySize), bytesFromC(value, valueSize), emitter.emit)
+	r := emitter.r
+	emitter.cursor = nil
+	extractEmitters.Put(emitter)
+	if r != 0 {
+		return r
+	}
+	return errorCodeC(err)
+}
@@ -0,0 +1,66 @@
+package wt_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+// tagsExtractor indexes records by each of comma separated tags in the value.
+type tagsExtractor struct{}
+
+func (tagsExtractor) Extract(key, value []byte, emit func(indexKey []byte)) error {
+	for _, tag := range bytes.Split(value, []byte(",")) {
+		if len(tag) > 0 {
+			emit(tag)
+		}
+	}
+	return nil
+}
+
+func TestExtractor(t *testing.T) {
+	db := wttest.NewDB(t)
+	err := db.Conn.AddExtractor("tags", tagsExtractor{})
+	require.NoError(t, err)
+
+	s := db.Session
+	err = s.Create("table:docs", wt.DataSourceCfg{
+		KeyFormat: "u", ValueFormat: "u", Columns: []string{"id", "tags"}})
+	require.NoError(t, err)
+	byTag := wt.IndexURI("table:docs", "byTag")
+	err = s.Create(byTag, wt.DataSourceCfg{Extractor: "tags", KeyFormat: "u"})
+	require.NoError(t, err)
+
+	c1, err := s.OpenCursor("table:docs")
+	require.NoError(t, err)
+	defer c1.Close()
+	require.NoError(t, c1.Insert([]byte("doc1"), []byte("go,db")))
+	require.NoError(t, c1.Insert([]byte("doc2"), []byte("db")))
+
+	readIndex := func() []string {
+		idx, err := s.OpenCursor(byTag)
+		require.NoError(t, err)
+		defer idx.Close()
+		var entries []string
+		for {
+			if err := idx.Next(); err != nil {
+				require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+				return entries
+			}
+			k, err := idx.Key()
+			require.NoError(t, err)
+			v, err := idx.Value()
+			require.NoError(t, err)
+			entries = append(entries, string(k)+"="+string(v))
+		}
+	}
+	require.EqualValues(t, []string{"db=go,db", "db=db", "go=go,db"}, readIndex())
+
+	// Index entries must be updated when records change.
+	require.NoError(t, c1.UpdateValue([]byte("doc2"), []byte("go")))
+	require.NoError(t, c1.RemoveKey([]byte("doc1")))
+	require.EqualValues(t, []string{"go=go"}, readIndex())
+}
@@ -0,0 +1,102 @@
+package wt
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrInjectedFault is returned by FaultFS for injected failures. WiredTiger sees it as EIO.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultFS wraps a FileSystem and injects failures into write and sync calls of its files.
+// Calls are counted across all files.
+type FaultFS struct {
+	FileSystem
+	mu        sync.Mutex
+	writes    int
+	syncs     int
+	failWrite int
+	failSync  int
+}
+
+// NewFaultFS wraps `fs` with a FaultFS. No failures are injected until FailWrite or
+// FailSync calls are made.
+func NewFaultFS(fs FileSystem) *FaultFS {
+	return &FaultFS{FileSystem: fs}
+}
+
+// FailWrite makes n-th write call, counting from this call, fail with ErrInjectedFault.
+// Zero disables write failure injection.
+func (f *FaultFS) FailWrite(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failWrite = 0
+	if n > 0 {
+		f.failWrite = f.writes + n
+	}
+}
+
+// FailSync makes n-th sync call, counting from this call, fail with ErrInjectedFault.
+// Zero disables sync failure injection.
+func (f *FaultFS) FailSync(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failSync = 0
+	if n > 0 {
+		f.failSync = f.syncs + n
+	}
+}
+
+// Writes returns total number of write calls made so far.
+func (f *FaultFS) Writes() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writes
+}
+
+// Syncs returns total number of sync calls made so far.
+func (f *FaultFS) Syncs() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.syncs
+}
+
+// Open implements FileSystem interface.
+func (f *FaultFS) Open(name string, flags OpenFlags) (File, error) {
+	file, err := f.FileSystem.Open(name, flags)
+	if err != nil {
+		return nil, err
+	}
+	return &faultFile{File: file, fs: f}, nil
+}
+
+// inject counts a call and returns true, if it needs to fail.
+func (f *FaultFS) inject(calls, failAt *int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	*calls++
+	if *failAt != 0 && *calls == *failAt {
+		*failAt = 0
+		return true
+	}
+	return false
+}
+
+type faultFile struct {
+	File
+	fs *FaultFS
+}
+
+func (f *faultFile) WriteAt(p []byte, off int64) (int, error) {
+	if f.fs.inject(&f.fs.writes, &f.fs.failWrite) {
+		return 0, ErrInjectedFault
+	}
+	return f.File.WriteAt(p, off)
+}
+
+func (f *faultFile) Sync() error {
+	if f.fs.inject(&f.fs.syncs, &f.fs.failSync) {
+		return ErrInjectedFault
+	}
+	return f.File.Sync()
+}
@@ -0,0 +1,227 @@
+package wt
+
+/*
+#include <errno.h>
+#include <stdlib.h>
+#include "callbacks.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"runtime/cgo"
+	"syscall"
+	"unsafe"
+)
+
+// FileSystem is a Go implementation of WT_FILE_SYSTEM. It can only be set during
+// wiredtiger_open call, using ConnCfg.FileSystem config, and WiredTiger then uses it
+// for all of its files, including the lock file, the metadata and the log. Names are
+// passed in exactly as WiredTiger constructs them, i.e. prefixed with the database path.
+//
+// Methods can be called concurrently from multiple goroutines. Errors that wrap
+// fs.ErrNotExist and fs.ErrExist are reported to WiredTiger as ENOENT and EEXIST,
+// syscall.Errno errors are reported as is, and all other errors are reported as EIO.
+type FileSystem interface {
+	// Open opens or creates a file. Directories are opened too, with OpenDirectory flag,
+	// so that they can be synced.
+	Open(name string, flags OpenFlags) (File, error)
+	// Exists returns true, if file exists.
+	Exists(name string) (bool, error)
+	// Remove removes a file.
+	Remove(name string) error
+	// Rename renames a file, replacing `to` file if it exists.
+	Rename(from, to string) error
+	// Size returns size of a file.
+	Size(name string) (int64, error)
+	// List returns names, relative to the directory, of files in `dir` directory that
+	// start with `prefix`.
+	List(dir, prefix string) ([]string, error)
+}
+
+// File is a Go implementation of WT_FILE_HANDLE. ReadAt must either read the whole
+// buffer or return an error.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+	// Size returns current size of the file.
+	Size() (int64, error)
+	// Sync flushes file to durable storage.
+	Sync() error
+	// Truncate changes size of the file.
+	Truncate(size int64) error
+	// Lock acquires or releases an exclusive lock on the file.
+	Lock(lock bool) error
+	// Close closes the file.
+	Close() error
+}
+
+// OpenFlags describes options for FileSystem.Open call.
+type OpenFlags uint32
+
+// Flags for FileSystem.Open call. Other flags, for example access pattern hints, can
+// be ignored.
+const (
+	OpenCreate    OpenFlags = C.WT_FS_OPEN_CREATE
+	OpenDurable   OpenFlags = C.WT_FS_OPEN_DURABLE
+	OpenExclusive OpenFlags = C.WT_FS_OPEN_EXCLUSIVE
+	OpenReadonly  OpenFlags = C.WT_FS_OPEN_READONLY
+	// OpenDirectory is set when opening a directory.
+	OpenDirectory OpenFlags = 1 << 31
+)
+
+// setFileSystem performs WT_CONNECTION::set_file_system call. Can only be called during
+// wiredtiger_open call, from 'wt_go_extension_init' entry point.
+func (c *Connection) setFileSystem(fileSystem FileSystem) error {
+	h := cgo.NewHandle(fileSystem)
+	r := C.wt_conn_set_file_system(c.c, C.uintptr_t(h))
+	if r != 0 {
+		h.Delete()
+	}
+	return c.connError(r, "set_file_system", "")
+}
+
+// fsErrorC converts errors, returned by FileSystem and File implementations, to
+// error codes that WiredTiger expects.
+func fsErrorC(err error) C.int {
+	if err == nil {
+		return 0
+	}
+	var errno syscall.Errno
+	var wtErr *Error
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return C.ENOENT
+	case errors.Is(err, fs.ErrExist):
+		return C.EEXIST
+	case errors.As(err, &errno):
+		return C.int(errno)
+	case errors.As(err, &wtErr):
+		return C.int(wtErr.Code)
+	}
+	return C.EIO
+}
+
+func fileSystemFromC(handle C.uintptr_t) FileSystem {
+	return cgo.Handle(handle).Value().(FileSystem)
+}
+
+func fileFromC(handle C.uintptr_t) File {
+	return cgo.Handle(handle).Value().(File)
+}
+
+//export goFSOpen
+func goFSOpen(
+	handle C.uintptr_t, name *C.char, directory C.int, flags C.uint32_t,
+	fileHandle *C.uintptr_t) C.int {
+	openFlags := OpenFlags(flags) &^ OpenDirectory
+	if directory != 0 {
+		openFlags |= OpenDirectory
+	}
+	f, err := fileSystemFromC(handle).Open(C.GoString(name), openFlags)
+	if err != nil {
+		return fsErrorC(err)
+	}
+	*fileHandle = C.uintptr_t(cgo.NewHandle(f))
+	return 0
+}
+
+//export goFSExists
+func goFSExists(handle C.uintptr_t, name *C.char, exists *C.int) C.int {
+	ok, err := fileSystemFromC(handle).Exists(C.GoString(name))
+	*exists = boolC(ok)
+	return fsErrorC(err)
+}
+
+//export goFSRemove
+func goFSRemove(handle C.uintptr_t, name *C.char) C.int {
+	return fsErrorC(fileSystemFromC(handle).Remove(C.GoString(name)))
+}
+
+//export goFSRename
+func goFSRename(handle C.uintptr_t, from, to *C.char) C.int {
+	return fsErrorC(fileSystemFromC(handle).Rename(C.GoString(from), C.GoString(to)))
+}
+
+//export goFSSize
+func goFSSize(handle C.uintptr_t, name *C.char, size *C.wt_off_t) C.int {
+	s, err := fileSystemFromC(handle).Size(C.GoString(name))
+	*size = C.wt_off_t(s)
+	return fsErrorC(err)
+}
+
+//export goFSList
+func goFSList(
+	handle C.uintptr_t, dir, prefix *C.char, single C.int,
+	dirList ***C.char, count *C.uint32_t) C.int {
+	*dirList, *count = nil, 0
+	names, err := fileSystemFromC(handle).List(C.GoString(dir), C.GoString(prefix))
+	if err != nil {
+		return fsErrorC(err)
+	}
+	if single != 0 && len(names) > 1 {
+		names = names[:1]
+	}
+	if len(names) == 0 {
+		return 0
+	}
+	listP := C.calloc(C.size_t(len(names)), C.size_t(unsafe.Sizeof((*C.char)(nil))))
+	if listP == nil {
+		return C.ENOMEM
+	}
+	list := unsafe.Slice((**C.char)(listP), len(names))
+	for idx, name := range names {
+		list[idx] = C.CString(name)
+	}
+	*dirList, *count = (**C.char)(listP), C.uint32_t(len(names))
+	return 0
+}
+
+//export goFileClose
+func goFileClose(handle C.uintptr_t) C.int {
+	err := fileFromC(handle).Close()
+	cgo.Handle(handle).Delete()
+	return fsErrorC(err)
+}
+
+//export goFileLock
+func goFileLock(handle C.uintptr_t, lock C.int) C.int {
+	return fsErrorC(fileFromC(handle).Lock(lock != 0))
+}
+
+//export goFileRead
+func goFileRead(handle C.uintptr_t, offset C.wt_off_t, size C.size_t, buf unsafe.Pointer) C.int {
+	n, err := fileFromC(handle).ReadAt(bytesFromC(buf, size), int64(offset))
+	if n == int(size) {
+		return 0
+	}
+	if err == nil || err == io.EOF {
+		return C.EIO // Short read.
+	}
+	return fsErrorC(err)
+}
+
+//export goFileSize
+func goFileSize(handle C.uintptr_t, size *C.wt_off_t) C.int {
+	s, err := fileFromC(handle).Size()
+	*size = C.wt_off_t(s)
+	return fsErrorC(err)
+}
+
+//export goFileSync
+func goFileSync(handle C.uintptr_t) C.int {
+	return fsErrorC(fileFromC(handle).Sync())
+}
+
+//export goFileTruncate
+func goFileTruncate(handle C.uintptr_t, size C.wt_off_t) C.int {
+	return fsErrorC(fileFromC(handle).Truncate(int64(size)))
+}
+
+//export goFileWrite
+func goFileWrite(handle C.uintptr_t, offset C.wt_off_t, size C.size_t, buf unsafe.Pointer) C.int {
+	_, err := fileFromC(handle).WriteAt(bytesFromC(buf, size), int64(offset))
+	return fsErrorC(err)
+}
@@ -0,0 +1,108 @@
+package wt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemFS(t *testing.T) {
+	dbDir := "/wt-memfs-test"
+	memFS := NewMemFS()
+	cfg := ConnCfg{Create: True, Log: "enabled", FileSystem: memFS}
+	c, err := Open(dbDir, cfg)
+	require.NoError(t, err)
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+	err = s.Create("table:test_table")
+	require.NoError(t, err)
+	c1, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	require.NoError(t, c1.Insert([]byte("key"), []byte("value")))
+	require.NoError(t, c1.Close())
+	require.NoError(t, s.Close())
+	require.NoError(t, c.Close())
+
+	_, err = os.Stat(dbDir)
+	require.True(t, os.IsNotExist(err))
+	names, err := memFS.List(dbDir, "test_table")
+	require.NoError(t, err)
+	require.EqualValues(t, []string{"test_table.wt"}, names)
+
+	// Reopen using the same in-memory file system.
+	c, err = Open(dbDir, ConnCfg{FileSystem: memFS})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, c.Close()) }()
+	s, err = c.OpenSession()
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+	c1, err = s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c1.Close()
+	v, err := c1.ReadValue([]byte("key"))
+	require.NoError(t, err)
+	require.EqualValues(t, "value", string(v))
+}
+
+func TestFaultFS(t *testing.T) {
+	faultFS := NewFaultFS(NewMemFS())
+	c, err := Open("/wt-faultfs-test", ConnCfg{Create: True, Log: "enabled", FileSystem: faultFS})
+	require.NoError(t, err)
+	// Connection might be unusable after injected failure, thus errors from Close
+	// are ignored.
+	defer c.Close()
+	s, err := c.OpenSession()
+	require.NoError(t, err)
+	err = s.Create("table:test_table")
+	require.NoError(t, err)
+	c1, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+
+	require.NoError(t, s.TxBegin())
+	require.NoError(t, c1.Insert([]byte("key1"), []byte("value1")))
+	require.NoError(t, s.TxCommit(TxCfg{Sync: True}))
+	require.NotZero(t, faultFS.Syncs())
+
+	faultFS.FailSync(1)
+	require.NoError(t, s.TxBegin())
+	require.NoError(t, c1.Insert([]byte("key2"), []byte("value2")))
+	require.Error(t, s.TxCommit(TxCfg{Sync: True}))
+}
+
+func TestMemFSFiles(t *testing.T) {
+	memFS := NewMemFS()
+	_, err := memFS.Open("/db/a", 0)
+	require.True(t, os.IsNotExist(err))
+	f, err := memFS.Open("/db/a", OpenCreate)
+	require.NoError(t, err)
+	_, err = memFS.Open("/db/a", OpenCreate|OpenExclusive)
+	require.True(t, os.IsExist(err))
+
+	_, err = f.WriteAt([]byte("hello"), 3)
+	require.NoError(t, err)
+	size, err := memFS.Size("/db/a")
+	require.NoError(t, err)
+	require.EqualValues(t, 8, size)
+	buf := make([]byte, 8)
+	_, err = f.ReadAt(buf, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, "\x00\x00\x00hello", string(buf))
+	_, err = f.ReadAt(buf, 1)
+	require.Error(t, err)
+
+	require.NoError(t, f.Truncate(4))
+	require.NoError(t, f.Truncate(6))
+	_, err = f.ReadAt(buf[:6], 0)
+	require.NoError(t, err)
+	require.EqualValues(t, "\x00\x00\x00h\x00\x00", string(buf[:6]))
+
+	require.NoError(t, memFS.Rename("/db/a", "/db/b"))
+	names, err := memFS.List("/db", "")
+	require.NoError(t, err)
+	require.EqualValues(t, []string{"b"}, names)
+	require.NoError(t, memFS.Remove("/db/b"))
+	ok, err := memFS.Exists("/db/b")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
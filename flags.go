@@ -1,4 +1,7 @@
 package wt
 
-// #cgo LDFLAGS: -l:libwiredtiger.a -l:libsnappy.a -l:libjemalloc.a -ldl -lstdc++ -pthread
+// -rdynamic is needed for WiredTiger to be able to find 'wt_go_extension_init' entry point
+// in the executable itself, see extension.go.
+
+// #cgo LDFLAGS: -l:libwiredtiger.a -l:libsnappy.a -l:libjemalloc.a -ldl -lstdc++ -pthread -rdynamic
 import "C"
@@ -1,8 +1,15 @@
 module github.com/zviadm/wt
 
-go 1.14
+go 1.23
 
 require (
+	github.com/klauspost/compress v1.17.11
+	github.com/pierrec/lz4/v4 v4.1.33
 	github.com/stretchr/testify v1.5.1
-	github.com/zviadm/zlog v0.0.0-20200326214804-bea93fc07ffa
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
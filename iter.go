@@ -0,0 +1,57 @@
+package wt
+
+import (
+	"iter"
+)
+
+// All returns iterator over all key/value pairs, for use with range-over-func loops:
+//
+//	for k, v := range c.All() {
+//		...
+//	}
+//	if err := c.Err(); err != nil {
+//		...
+//	}
+//
+// Key and value slices point to `C` memory and are only valid until the next iteration.
+// Iteration stops at the end of the table or on the first error, which can be checked
+// using Err call once loop finishes. Cursor is reset once loop finishes, including
+// when loop breaks early.
+func (c *Cursor) All() iter.Seq2[[]byte, []byte] {
+	return c.Seq(RangeOpts{})
+}
+
+// Between returns iterator over key/value pairs with keys in [start, end) range. Empty
+// `start` or `end` means no bound. See All for more details.
+func (c *Cursor) Between(start, end []byte) iter.Seq2[[]byte, []byte] {
+	return c.Seq(RangeOpts{Start: start, End: end})
+}
+
+// Seq returns iterator over key/value pairs in the range described by `opts`. See All
+// for more details. Error of the previous iteration is cleared once Seq is called.
+func (c *Cursor) Seq(opts RangeOpts) iter.Seq2[[]byte, []byte] {
+	c.iterErr = nil
+	return func(yield func([]byte, []byte) bool) {
+		it := c.Range(opts)
+		// Deferred, so that cursor is reset even if loop body panics or calls
+		// runtime.Goexit.
+		defer func() {
+			c.iterErr = it.Err()
+			if err := it.Close(); err != nil && c.iterErr == nil {
+				c.iterErr = err
+			}
+		}()
+		for it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns error that stopped the last iteration started with All, Between
+// or Seq calls. Returns nil, if iteration finished successfully, or if the last
+// created iterator hasn't been used yet.
+func (c *Cursor) Err() error {
+	return c.iterErr
+}
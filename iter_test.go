@@ -0,0 +1,63 @@
+package wt_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestCursorIter(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < 5; i++ {
+		err := c.Insert([]byte("testkey"+strconv.Itoa(i)), []byte("testval"+strconv.Itoa(i)))
+		require.NoError(t, err)
+	}
+
+	var keys []string
+	for k, v := range c.All() {
+		require.EqualValues(t, "testval", string(v[:7]))
+		keys = append(keys, string(k))
+	}
+	require.NoError(t, c.Err())
+	require.EqualValues(t, []string{"testkey0", "testkey1", "testkey2", "testkey3", "testkey4"}, keys)
+
+	keys = nil
+	for k := range c.Between([]byte("testkey1"), []byte("testkey3")) {
+		keys = append(keys, string(k))
+	}
+	require.NoError(t, c.Err())
+	require.EqualValues(t, []string{"testkey1", "testkey2"}, keys)
+
+	keys = nil
+	for k := range c.Seq(wt.RangeOpts{Reverse: true}) {
+		keys = append(keys, string(k))
+		if len(keys) == 2 {
+			break
+		}
+	}
+	require.NoError(t, c.Err())
+	require.EqualValues(t, []string{"testkey4", "testkey3"}, keys)
+
+	// Cursor must be reset after loop breaks early.
+	k, _, err := c.NextInto(nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey0", string(k))
+	require.NoError(t, c.Reset())
+
+	// Cursor must be reset even if loop body panics.
+	func() {
+		defer func() { require.NotNil(t, recover()) }()
+		for range c.All() {
+			panic("loop body panic")
+		}
+	}()
+	k, _, err = c.NextInto(nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey0", string(k))
+}
@@ -0,0 +1,475 @@
+// Package keys implements order-preserving encodings for composite keys. Encoded keys
+// compare with bytes.Compare, which is WiredTiger's default collation, in the same order as
+// the original tuples compare element by element.
+//
+// Every encoding is prefix-free, thus encoded values can be concatenated to build tuples:
+//
+//	key := keys.AppendString(nil, tenant)
+//	key = keys.AppendInt64Desc(key, timestamp)
+//	key = keys.AppendUint64(key, id)
+//
+// Same keys can be built with Encode and decoded with Decode, using Desc wrapper for
+// descending elements:
+//
+//	key, err := keys.Encode(tenant, keys.Desc(timestamp), id)
+//	...
+//	_, err = keys.Decode(key, &tenant, keys.Desc(&timestamp), &id)
+package keys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrCorrupt is returned when decoding data that wasn't produced by matching encoding.
+var ErrCorrupt = errors.New("keys: corrupt encoding")
+
+// Strings and byte slices are terminated with `escapeByte, terminatorByte` sequence.
+// Each `escapeByte` in the data is escaped as `escapeByte, escapedByte`.
+const (
+	escapeByte     = 0x00
+	terminatorByte = 0x01
+	escapedByte    = 0xff
+)
+
+// AppendUint64 appends fixed 8 byte encoding of `v` to `dst`.
+func AppendUint64(dst []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(dst, b[:]...)
+}
+
+// AppendUint64Desc is same as AppendUint64, but in descending order.
+func AppendUint64Desc(dst []byte, v uint64) []byte {
+	return AppendUint64(dst, ^v)
+}
+
+// AppendInt64 appends fixed 8 byte encoding of `v` to `dst`. Sign bit is flipped, so that
+// negative values sort before positive ones.
+func AppendInt64(dst []byte, v int64) []byte {
+	return AppendUint64(dst, uint64(v)^(1<<63))
+}
+
+// AppendInt64Desc is same as AppendInt64, but in descending order.
+func AppendInt64Desc(dst []byte, v int64) []byte {
+	return AppendUint64Desc(dst, uint64(v)^(1<<63))
+}
+
+// AppendFloat64 appends fixed 8 byte encoding of `v` to `dst`. Negative zero sorts
+// before positive zero, and NaNs sort either before all negative or after all
+// positive values, depending on their sign bit.
+func AppendFloat64(dst []byte, v float64) []byte {
+	return AppendUint64(dst, floatToUint64(v))
+}
+
+// AppendFloat64Desc is same as AppendFloat64, but in descending order.
+func AppendFloat64Desc(dst []byte, v float64) []byte {
+	return AppendUint64Desc(dst, floatToUint64(v))
+}
+
+// AppendBool appends single byte encoding of `v` to `dst`. False sorts before true.
+func AppendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
+}
+
+// AppendBoolDesc is same as AppendBool, but in descending order.
+func AppendBoolDesc(dst []byte, v bool) []byte {
+	return AppendBool(dst, !v)
+}
+
+// AppendBytes appends escaped and terminated encoding of `v` to `dst`. Encoding takes
+// len(v) + 2 bytes, plus one extra byte for every 0x00 byte in `v`.
+func AppendBytes(dst []byte, v []byte) []byte {
+	for _, b := range v {
+		if b == escapeByte {
+			dst = append(dst, escapeByte, escapedByte)
+		} else {
+			dst = append(dst, b)
+		}
+	}
+	return append(dst, escapeByte, terminatorByte)
+}
+
+// AppendBytesDesc is same as AppendBytes, but in descending order.
+func AppendBytesDesc(dst []byte, v []byte) []byte {
+	n := len(dst)
+	dst = AppendBytes(dst, v)
+	invert(dst[n:])
+	return dst
+}
+
+// AppendString is same as AppendBytes, but for strings.
+func AppendString(dst []byte, v string) []byte {
+	for i := 0; i < len(v); i++ {
+		if v[i] == escapeByte {
+			dst = append(dst, escapeByte, escapedByte)
+		} else {
+			dst = append(dst, v[i])
+		}
+	}
+	return append(dst, escapeByte, terminatorByte)
+}
+
+// AppendStringDesc is same as AppendString, but in descending order.
+func AppendStringDesc(dst []byte, v string) []byte {
+	n := len(dst)
+	dst = AppendString(dst, v)
+	invert(dst[n:])
+	return dst
+}
+
+// ReadUint64 decodes value encoded with AppendUint64 and returns it together with
+// remaining part of `buf`.
+func ReadUint64(buf []byte) (uint64, []byte, error) {
+	if len(buf) < 8 {
+		return 0, buf, ErrCorrupt
+	}
+	return binary.BigEndian.Uint64(buf), buf[8:], nil
+}
+
+// ReadUint64Desc decodes value encoded with AppendUint64Desc.
+func ReadUint64Desc(buf []byte) (uint64, []byte, error) {
+	v, rest, err := ReadUint64(buf)
+	return ^v, rest, err
+}
+
+// ReadInt64 decodes value encoded with AppendInt64.
+func ReadInt64(buf []byte) (int64, []byte, error) {
+	v, rest, err := ReadUint64(buf)
+	return int64(v ^ (1 << 63)), rest, err
+}
+
+// ReadInt64Desc decodes value encoded with AppendInt64Desc.
+func ReadInt64Desc(buf []byte) (int64, []byte, error) {
+	v, rest, err := ReadUint64Desc(buf)
+	return int64(v ^ (1 << 63)), rest, err
+}
+
+// ReadFloat64 decodes value encoded with AppendFloat64.
+func ReadFloat64(buf []byte) (float64, []byte, error) {
+	v, rest, err := ReadUint64(buf)
+	return uint64ToFloat(v), rest, err
+}
+
+// ReadFloat64Desc decodes value encoded with AppendFloat64Desc.
+func ReadFloat64Desc(buf []byte) (float64, []byte, error) {
+	v, rest, err := ReadUint64Desc(buf)
+	return uint64ToFloat(v), rest, err
+}
+
+// ReadBool decodes value encoded with AppendBool.
+func ReadBool(buf []byte) (bool, []byte, error) {
+	if len(buf) < 1 || buf[0] > 1 {
+		return false, buf, ErrCorrupt
+	}
+	return buf[0] == 1, buf[1:], nil
+}
+
+// ReadBoolDesc decodes value encoded with AppendBoolDesc.
+func ReadBoolDesc(buf []byte) (bool, []byte, error) {
+	v, rest, err := ReadBool(buf)
+	return !v, rest, err
+}
+
+// ReadBytes decodes value encoded with AppendBytes, appending it to `dst`.
+func ReadBytes(dst, buf []byte) ([]byte, []byte, error) {
+	return readEscaped(dst, buf, 0)
+}
+
+// ReadBytesDesc decodes value encoded with AppendBytesDesc, appending it to `dst`.
+func ReadBytesDesc(dst, buf []byte) ([]byte, []byte, error) {
+	return readEscaped(dst, buf, 0xff)
+}
+
+// ReadString decodes value encoded with AppendString.
+func ReadString(buf []byte) (string, []byte, error) {
+	v, rest, err := readEscaped(nil, buf, 0)
+	return string(v), rest, err
+}
+
+// ReadStringDesc decodes value encoded with AppendStringDesc.
+func ReadStringDesc(buf []byte) (string, []byte, error) {
+	v, rest, err := readEscaped(nil, buf, 0xff)
+	return string(v), rest, err
+}
+
+// readEscaped decodes escaped and terminated data. Every byte of `buf` is XORed with
+// `mask` before decoding, to support inverted, descending encoding.
+func readEscaped(dst, buf []byte, mask byte) ([]byte, []byte, error) {
+	for i := 0; i < len(buf); i++ {
+		b := buf[i] ^ mask
+		if b != escapeByte {
+			dst = append(dst, b)
+			continue
+		}
+		if i+1 >= len(buf) {
+			break
+		}
+		switch buf[i+1] ^ mask {
+		case terminatorByte:
+			return dst, buf[i+2:], nil
+		case escapedByte:
+			dst = append(dst, escapeByte)
+			i++
+		default:
+			return dst, buf, ErrCorrupt
+		}
+	}
+	return dst, buf, ErrCorrupt
+}
+
+func floatToUint64(v float64) uint64 {
+	b := math.Float64bits(v)
+	if b&(1<<63) != 0 {
+		return ^b
+	}
+	return b | (1 << 63)
+}
+
+func uint64ToFloat(b uint64) float64 {
+	if b&(1<<63) != 0 {
+		return math.Float64frombits(b &^ (1 << 63))
+	}
+	return math.Float64frombits(^b)
+}
+
+func invert(b []byte) {
+	for i := range b {
+		b[i] = ^b[i]
+	}
+}
+
+type desc struct {
+	v interface{}
+}
+
+// Desc wraps value for Encode, or pointer for Decode, to use descending order encoding.
+func Desc(v interface{}) interface{} {
+	return desc{v: v}
+}
+
+// Encode encodes `values` as a tuple. See Append for details.
+func Encode(values ...interface{}) ([]byte, error) {
+	return Append(nil, values...)
+}
+
+// Append appends tuple encoding of `values` to `dst`. Supported types are: all integer types,
+// float32, float64, bool, string and []byte. Signed integers are encoded same as int64, and
+// unsigned integers same as uint64. Values wrapped with Desc are encoded in descending order.
+func Append(dst []byte, values ...interface{}) ([]byte, error) {
+	for idx, v := range values {
+		isDesc := false
+		if d, ok := v.(desc); ok {
+			v, isDesc = d.v, true
+		}
+		var err error
+		dst, err = appendValue(dst, v, isDesc)
+		if err != nil {
+			return dst, fmt.Errorf("keys: value %d: %w", idx, err)
+		}
+	}
+	return dst, nil
+}
+
+func appendValue(dst []byte, v interface{}, isDesc bool) ([]byte, error) {
+	switch v := v.(type) {
+	case int:
+		return appendInt64(dst, int64(v), isDesc), nil
+	case int8:
+		return appendInt64(dst, int64(v), isDesc), nil
+	case int16:
+		return appendInt64(dst, int64(v), isDesc), nil
+	case int32:
+		return appendInt64(dst, int64(v), isDesc), nil
+	case int64:
+		return appendInt64(dst, v, isDesc), nil
+	case uint:
+		return appendUint64(dst, uint64(v), isDesc), nil
+	case uint8:
+		return appendUint64(dst, uint64(v), isDesc), nil
+	case uint16:
+		return appendUint64(dst, uint64(v), isDesc), nil
+	case uint32:
+		return appendUint64(dst, uint64(v), isDesc), nil
+	case uint64:
+		return appendUint64(dst, v, isDesc), nil
+	case float32:
+		return appendFloat64(dst, float64(v), isDesc), nil
+	case float64:
+		return appendFloat64(dst, v, isDesc), nil
+	case bool:
+		if isDesc {
+			return AppendBoolDesc(dst, v), nil
+		}
+		return AppendBool(dst, v), nil
+	case string:
+		if isDesc {
+			return AppendStringDesc(dst, v), nil
+		}
+		return AppendString(dst, v), nil
+	case []byte:
+		if isDesc {
+			return AppendBytesDesc(dst, v), nil
+		}
+		return AppendBytes(dst, v), nil
+	}
+	return dst, fmt.Errorf("unsupported type: %T", v)
+}
+
+func appendInt64(dst []byte, v int64, isDesc bool) []byte {
+	if isDesc {
+		return AppendInt64Desc(dst, v)
+	}
+	return AppendInt64(dst, v)
+}
+
+func appendUint64(dst []byte, v uint64, isDesc bool) []byte {
+	if isDesc {
+		return AppendUint64Desc(dst, v)
+	}
+	return AppendUint64(dst, v)
+}
+
+func appendFloat64(dst []byte, v float64, isDesc bool) []byte {
+	if isDesc {
+		return AppendFloat64Desc(dst, v)
+	}
+	return AppendFloat64(dst, v)
+}
+
+// Decode decodes tuple encoded with Encode into `ptrs` and returns remaining part of `buf`.
+// Pointers must be of the same types as values that were encoded, and pointers wrapped with
+// Desc must match values that were wrapped with Desc.
+func Decode(buf []byte, ptrs ...interface{}) ([]byte, error) {
+	for idx, p := range ptrs {
+		isDesc := false
+		if d, ok := p.(desc); ok {
+			p, isDesc = d.v, true
+		}
+		var err error
+		buf, err = decodeValue(buf, p, isDesc)
+		if err != nil {
+			return buf, fmt.Errorf("keys: value %d: %w", idx, err)
+		}
+	}
+	return buf, nil
+}
+
+func decodeValue(buf []byte, p interface{}, isDesc bool) ([]byte, error) {
+	switch p := p.(type) {
+	case *int, *int8, *int16, *int32, *int64:
+		read := ReadInt64
+		if isDesc {
+			read = ReadInt64Desc
+		}
+		v, rest, err := read(buf)
+		if err != nil {
+			return buf, err
+		}
+		return rest, setInt(p, v)
+	case *uint, *uint8, *uint16, *uint32, *uint64:
+		read := ReadUint64
+		if isDesc {
+			read = ReadUint64Desc
+		}
+		v, rest, err := read(buf)
+		if err != nil {
+			return buf, err
+		}
+		return rest, setUint(p, v)
+	case *float32, *float64:
+		read := ReadFloat64
+		if isDesc {
+			read = ReadFloat64Desc
+		}
+		v, rest, err := read(buf)
+		if err != nil {
+			return buf, err
+		}
+		if p32, ok := p.(*float32); ok {
+			*p32 = float32(v)
+		} else {
+			*p.(*float64) = v
+		}
+		return rest, nil
+	case *bool:
+		read := ReadBool
+		if isDesc {
+			read = ReadBoolDesc
+		}
+		v, rest, err := read(buf)
+		if err != nil {
+			return buf, err
+		}
+		*p = v
+		return rest, nil
+	case *string:
+		read := ReadString
+		if isDesc {
+			read = ReadStringDesc
+		}
+		v, rest, err := read(buf)
+		if err != nil {
+			return buf, err
+		}
+		*p = v
+		return rest, nil
+	case *[]byte:
+		read := ReadBytes
+		if isDesc {
+			read = ReadBytesDesc
+		}
+		v, rest, err := read(nil, buf)
+		if err != nil {
+			return buf, err
+		}
+		*p = v
+		return rest, nil
+	}
+	return buf, fmt.Errorf("unsupported type: %T", p)
+}
+
+func setInt(p interface{}, v int64) error {
+	var ok bool
+	switch p := p.(type) {
+	case *int:
+		*p, ok = int(v), int64(int(v)) == v
+	case *int8:
+		*p, ok = int8(v), int64(int8(v)) == v
+	case *int16:
+		*p, ok = int16(v), int64(int16(v)) == v
+	case *int32:
+		*p, ok = int32(v), int64(int32(v)) == v
+	case *int64:
+		*p, ok = v, true
+	}
+	if !ok {
+		return fmt.Errorf("value overflows %T: %d", p, v)
+	}
+	return nil
+}
+
+func setUint(p interface{}, v uint64) error {
+	var ok bool
+	switch p := p.(type) {
+	case *uint:
+		*p, ok = uint(v), uint64(uint(v)) == v
+	case *uint8:
+		*p, ok = uint8(v), uint64(uint8(v)) == v
+	case *uint16:
+		*p, ok = uint16(v), uint64(uint16(v)) == v
+	case *uint32:
+		*p, ok = uint32(v), uint64(uint32(v)) == v
+	case *uint64:
+		*p, ok = v, true
+	}
+	if !ok {
+		return fmt.Errorf("value overflows %T: %d", p, v)
+	}
+	return nil
+}
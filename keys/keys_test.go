@@ -0,0 +1,131 @@
+package keys
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	key, err := Encode(
+		"tenant\x00one", Desc(int64(-5)), uint64(7), 1.5, Desc(true), []byte{0, 1, 0xff}, Desc("abc"))
+	require.NoError(t, err)
+
+	var tenant string
+	var ts int64
+	var id uint64
+	var f float64
+	var b bool
+	var raw []byte
+	var s string
+	rest, err := Decode(key, &tenant, Desc(&ts), &id, &f, Desc(&b), &raw, Desc(&s))
+	require.NoError(t, err)
+	require.Len(t, rest, 0)
+	require.EqualValues(t, "tenant\x00one", tenant)
+	require.EqualValues(t, -5, ts)
+	require.EqualValues(t, 7, id)
+	require.EqualValues(t, 1.5, f)
+	require.True(t, b)
+	require.EqualValues(t, []byte{0, 1, 0xff}, raw)
+	require.EqualValues(t, "abc", s)
+
+	// Decoding can stop early, leaving the rest of the tuple.
+	rest, err = Decode(key, &tenant)
+	require.NoError(t, err)
+	require.EqualValues(t, key[len("tenant\x00one")+3:], rest)
+
+	_, err = Encode(struct{}{})
+	require.Error(t, err)
+	_, err = Decode(key[:5], &tenant)
+	require.Error(t, err)
+	_, err = Decode(key[:3], &id)
+	require.Error(t, err)
+	var small int8
+	k, err := Encode(1000)
+	require.NoError(t, err)
+	_, err = Decode(k, &small)
+	require.Error(t, err)
+}
+
+func TestOrdering(t *testing.T) {
+	strs := []string{"", "\x00", "\x00\x00", "\x00\x01", "\x01", "a", "a\x00", "a\x00b", "ab", "b", "\xff", "\xff\xff"}
+	for i := 1; i < len(strs); i++ {
+		require.Less(t, string(AppendString(nil, strs[i-1])), string(AppendString(nil, strs[i])))
+		require.Greater(t,
+			string(AppendStringDesc(nil, strs[i-1])), string(AppendStringDesc(nil, strs[i])))
+	}
+	ints := []int64{math.MinInt64, -1 << 40, -256, -1, 0, 1, 255, 1 << 40, math.MaxInt64}
+	for i := 1; i < len(ints); i++ {
+		require.Less(t, string(AppendInt64(nil, ints[i-1])), string(AppendInt64(nil, ints[i])))
+		require.Greater(t,
+			string(AppendInt64Desc(nil, ints[i-1])), string(AppendInt64Desc(nil, ints[i])))
+	}
+	floats := []float64{
+		math.Inf(-1), -math.MaxFloat64, -1, -math.SmallestNonzeroFloat64, math.Copysign(0, -1),
+		0, math.SmallestNonzeroFloat64, 1, math.MaxFloat64, math.Inf(1)}
+	for i := 1; i < len(floats); i++ {
+		require.Less(t, string(AppendFloat64(nil, floats[i-1])), string(AppendFloat64(nil, floats[i])))
+		require.Greater(t,
+			string(AppendFloat64Desc(nil, floats[i-1])), string(AppendFloat64Desc(nil, floats[i])))
+	}
+	require.Less(t, string(AppendBool(nil, false)), string(AppendBool(nil, true)))
+	require.Greater(t, string(AppendBoolDesc(nil, false)), string(AppendBoolDesc(nil, true)))
+}
+
+// FuzzTupleOrder checks that bytes.Compare order of encoded tuples matches
+// the order of tuples themselves, and that tuples decode back to same values.
+func FuzzTupleOrder(f *testing.F) {
+	f.Add("a", int64(1), 1.0, []byte{0}, "a", int64(1), 1.0, []byte{0})
+	f.Add("a", int64(-1), -1.0, []byte{}, "a\x00", int64(1), 0.0, []byte{0})
+	f.Add("", int64(math.MinInt64), math.Inf(-1), []byte{0xff}, "\x00", int64(math.MaxInt64), math.Inf(1), []byte{0, 0})
+	f.Fuzz(func(t *testing.T,
+		s1 string, i1 int64, f1 float64, b1 []byte,
+		s2 string, i2 int64, f2 float64, b2 []byte) {
+		if math.IsNaN(f1) || math.IsNaN(f2) {
+			return
+		}
+		if f1 == 0 && f2 == 0 {
+			f1, f2 = 0, 0 // Negative and positive zeros are ordered differently.
+		}
+		k1, err := Encode(s1, Desc(i1), f1, Desc(b1))
+		require.NoError(t, err)
+		k2, err := Encode(s2, Desc(i2), f2, Desc(b2))
+		require.NoError(t, err)
+
+		expected := strings.Compare(s1, s2)
+		if expected == 0 {
+			expected = -cmp(i1 < i2, i1 > i2)
+		}
+		if expected == 0 {
+			expected = cmp(f1 < f2, f1 > f2)
+		}
+		if expected == 0 {
+			expected = -bytes.Compare(b1, b2)
+		}
+		require.EqualValues(t, expected, bytes.Compare(k1, k2))
+
+		var s string
+		var i int64
+		var fl float64
+		var b []byte
+		rest, err := Decode(k1, &s, Desc(&i), &fl, Desc(&b))
+		require.NoError(t, err)
+		require.Len(t, rest, 0)
+		require.EqualValues(t, s1, s)
+		require.EqualValues(t, i1, i)
+		require.EqualValues(t, f1, fl)
+		require.True(t, bytes.Equal(b1, b))
+	})
+}
+
+func cmp(less, greater bool) int {
+	if less {
+		return -1
+	} else if greater {
+		return 1
+	}
+	return 0
+}
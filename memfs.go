@@ -0,0 +1,183 @@
+package wt
+
+import (
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemFS is an in-memory FileSystem. Data written to MemFS outlives connections that use
+// it, thus same MemFS can be used to reopen the database. MemFS doesn't lock files, since
+// it can't be shared across processes anyway.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+}
+
+type memFile struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+// NewMemFS creates new empty in-memory FileSystem.
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(map[string]*memFile)}
+}
+
+// Open implements FileSystem interface.
+func (m *MemFS) Open(name string, flags OpenFlags) (File, error) {
+	if flags&OpenDirectory != 0 {
+		return memDir{}, nil // Directories are implicit.
+	}
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[name]
+	if ok && flags&OpenExclusive != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+	if !ok {
+		if flags&OpenCreate == 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		f = &memFile{}
+		m.files[name] = f
+	}
+	return f, nil
+}
+
+// Exists implements FileSystem interface.
+func (m *MemFS) Exists(name string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[filepath.Clean(name)]
+	return ok, nil
+}
+
+// Remove implements FileSystem interface.
+func (m *MemFS) Remove(name string) error {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// Rename implements FileSystem interface.
+func (m *MemFS) Rename(from, to string) error {
+	from, to = filepath.Clean(from), filepath.Clean(to)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[from]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: from, Err: fs.ErrNotExist}
+	}
+	delete(m.files, from)
+	m.files[to] = f
+	return nil
+}
+
+// Size implements FileSystem interface.
+func (m *MemFS) Size(name string) (int64, error) {
+	name = filepath.Clean(name)
+	m.mu.Lock()
+	f, ok := m.files[name]
+	m.mu.Unlock()
+	if !ok {
+		return 0, &fs.PathError{Op: "size", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.Size()
+}
+
+// List implements FileSystem interface.
+func (m *MemFS) List(dir, prefix string) ([]string, error) {
+	dir = filepath.Clean(dir)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.files {
+		if filepath.Dir(name) != dir {
+			continue
+		}
+		if base := filepath.Base(name); strings.HasPrefix(base, prefix) {
+			names = append(names, base)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if end := off + int64(len(p)); end > int64(len(f.data)) {
+		f.resize(end)
+	}
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memFile) Size() (int64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return int64(len(f.data)), nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resize(size)
+	return nil
+}
+
+// resize must be called with f.mu held.
+func (f *memFile) resize(size int64) {
+	if size <= int64(len(f.data)) {
+		f.data = f.data[:size]
+		return
+	}
+	if size <= int64(cap(f.data)) {
+		tail := f.data[len(f.data):size]
+		for i := range tail {
+			tail[i] = 0
+		}
+		f.data = f.data[:size]
+		return
+	}
+	data := make([]byte, size, 2*size)
+	copy(data, f.data)
+	f.data = data
+}
+
+func (f *memFile) Sync() error          { return nil }
+func (f *memFile) Lock(lock bool) error { return nil }
+func (f *memFile) Close() error         { return nil }
+
+// memDir is a File for directories. Directories can only be synced.
+type memDir struct{}
+
+func (memDir) ReadAt(p []byte, off int64) (int, error)  { return 0, fs.ErrInvalid }
+func (memDir) WriteAt(p []byte, off int64) (int, error) { return 0, fs.ErrInvalid }
+func (memDir) Size() (int64, error)                     { return 0, nil }
+func (memDir) Sync() error                              { return nil }
+func (memDir) Truncate(size int64) error                { return fs.ErrInvalid }
+func (memDir) Lock(lock bool) error                     { return nil }
+func (memDir) Close() error                             { return nil }
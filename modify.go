@@ -0,0 +1,155 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include <wiredtiger.h>
+
+// Modifications are passed in as a single data buffer and an array of
+// (data size, offset, size) triplets, since WT_MODIFY entries can't point to
+// Go memory from Go allocated array.
+static int _cursor_modify(
+	WT_CURSOR *cursor,
+	const uint8_t *data, const size_t *sizes, int n) {
+	WT_MODIFY *entries = calloc((size_t)n, sizeof(WT_MODIFY));
+	if (entries == NULL) {
+		return WT_ERROR;
+	}
+	for (int i = 0; i < n; i++) {
+		entries[i].data.data = data;
+		entries[i].data.size = sizes[3*i];
+		entries[i].offset = sizes[3*i+1];
+		entries[i].size = sizes[3*i+2];
+		data += sizes[3*i];
+	}
+	int r = cursor->modify(cursor, entries, n);
+	free(entries);
+	return r;
+}
+int wt_cursor_modify(
+	WT_CURSOR *cursor,
+	const uint8_t *data, const size_t *sizes, int n) {
+	return _cursor_modify(cursor, data, sizes, n);
+}
+int wt_cursor_modify_and_reset(
+	WT_CURSOR *cursor,
+	const void *key, size_t key_size,
+	const uint8_t *data, const size_t *sizes, int n) {
+	WT_ITEM item;
+	item.data = key;
+	item.size = key_size;
+	cursor->set_key(cursor, &item);
+	int r = _cursor_modify(cursor, data, sizes, n);
+	if (r != 0) {
+		return r;
+	}
+	return cursor->reset(cursor);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// Modification mirrors WT_MODIFY structure. It replaces Size bytes at Offset in
+// the value with Data. Data can be of different length than Size, thus modifications
+// can both grow and shrink the value.
+type Modification struct {
+	Data   []byte
+	Offset int
+	Size   int
+}
+
+// Modify performs WT_CURSOR::modify call on the element that cursor is pointing to.
+// Modifications are applied in order. Must be called inside a transaction, otherwise
+// ErrTxRequired is returned.
+func (c *Cursor) Modify(mods []Modification) error {
+	if !c.s.InTx() {
+		return ErrTxRequired
+	}
+	if len(mods) == 0 {
+		return nil
+	}
+	dataP, sizesP := modificationsC(mods)
+	r := C.wt_cursor_modify(c.c, dataP, sizesP, C.int(len(mods)))
+	return c.cursorError(r, "modify", nil)
+}
+
+// ModifyValue performs WT_CURSOR::modify call for a specific key. Cursor is reset
+// after this call. Must be called inside a transaction, otherwise ErrTxRequired is
+// returned.
+func (c *Cursor) ModifyValue(key []byte, mods []Modification) error {
+	if !c.s.InTx() {
+		return ErrTxRequired
+	}
+	if len(mods) == 0 {
+		return nil
+	}
+	keyP := unsafe.Pointer(&key[0])
+	dataP, sizesP := modificationsC(mods)
+	r := C.wt_cursor_modify_and_reset(
+		c.c, keyP, C.size_t(len(key)), dataP, sizesP, C.int(len(mods)))
+	return c.cursorError(r, "modify", key)
+}
+
+func modificationsC(mods []Modification) (*C.uint8_t, *C.size_t) {
+	dataSize := 0
+	for _, m := range mods {
+		dataSize += len(m.Data)
+	}
+	data := make([]byte, 0, dataSize+1) // +1 to always have valid pointer.
+	sizes := make([]C.size_t, 0, 3*len(mods))
+	for _, m := range mods {
+		data = append(data, m.Data...)
+		sizes = append(sizes, C.size_t(len(m.Data)), C.size_t(m.Offset), C.size_t(m.Size))
+	}
+	return (*C.uint8_t)(unsafe.Pointer(&data[:1][0])), &sizes[0]
+}
+
+// modifyGapMin is the smallest gap of unchanged bytes for which it is worth to split
+// modification in two. Each modification has its own overhead, so small gaps are
+// cheaper to just rewrite.
+const modifyGapMin = 16
+
+// CalcModify computes list of modifications that transform `oldValue` into `newValue`,
+// for use with Modify and ModifyValue calls. Common prefix and suffix of both values are
+// left untouched. If changed parts are of the same length, only byte ranges that actually
+// differ are replaced. Returns nil, if values are equal.
+func CalcModify(oldValue, newValue []byte) []Modification {
+	prefix := 0
+	for prefix < len(oldValue) && prefix < len(newValue) && oldValue[prefix] == newValue[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldValue)-prefix && suffix < len(newValue)-prefix &&
+		oldValue[len(oldValue)-1-suffix] == newValue[len(newValue)-1-suffix] {
+		suffix++
+	}
+	oldMid := oldValue[prefix : len(oldValue)-suffix]
+	newMid := newValue[prefix : len(newValue)-suffix]
+	if len(oldMid) == 0 && len(newMid) == 0 {
+		return nil
+	}
+	if len(oldMid) != len(newMid) {
+		return []Modification{{Data: newMid, Offset: prefix, Size: len(oldMid)}}
+	}
+	var mods []Modification
+	start, end := -1, -1 // Current run of differing bytes.
+	for i := range newMid {
+		if oldMid[i] == newMid[i] {
+			continue
+		}
+		if start >= 0 && i-end >= modifyGapMin {
+			mods = append(mods, Modification{
+				Data: newMid[start:end], Offset: prefix + start, Size: end - start})
+			start = -1
+		}
+		if start < 0 {
+			start = i
+		}
+		end = i + 1
+	}
+	mods = append(mods, Modification{
+		Data: newMid[start:end], Offset: prefix + start, Size: end - start})
+	return mods
+}
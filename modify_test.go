@@ -0,0 +1,92 @@
+package wt_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestCursorModify(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+
+	oldValue := []byte(`{"name": "test", "count": 10, "tags": ["a", "b"]}`)
+	newValue := []byte(`{"name": "test", "count": 11, "tags": ["a", "b", "c"]}`)
+	err = c.Insert([]byte("testkey1"), oldValue)
+	require.NoError(t, err)
+
+	mods := wt.CalcModify(oldValue, newValue)
+	err = c.ModifyValue([]byte("testkey1"), mods)
+	require.EqualValues(t, wt.ErrTxRequired, err)
+
+	require.NoError(t, s.TxBegin())
+	err = c.ModifyValue([]byte("testkey1"), mods)
+	require.NoError(t, err)
+	require.NoError(t, s.TxCommit())
+	v, err := c.ReadValue([]byte("testkey1"))
+	require.NoError(t, err)
+	require.EqualValues(t, newValue, v)
+
+	require.NoError(t, s.TxBegin())
+	err = c.Search([]byte("testkey1"))
+	require.NoError(t, err)
+	err = c.Modify([]wt.Modification{{Data: []byte("XX"), Offset: 1, Size: 1}})
+	require.NoError(t, err)
+	require.NoError(t, s.TxCommit())
+	v, err = c.ReadValue([]byte("testkey1"))
+	require.NoError(t, err)
+	require.EqualValues(t, `{XX"name"`, string(v[:9]))
+
+	require.NoError(t, s.TxBegin())
+	err = c.ModifyValue([]byte("testkey2"), mods)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+	require.NoError(t, s.TxRollback())
+}
+
+func TestCalcModify(t *testing.T) {
+	require.Nil(t, wt.CalcModify([]byte("test"), []byte("test")))
+	require.EqualValues(t,
+		[]wt.Modification{{Data: []byte("XY"), Offset: 2, Size: 1}},
+		wt.CalcModify([]byte("abcde"), []byte("abXYde")))
+	require.EqualValues(t,
+		[]wt.Modification{{Data: []byte(""), Offset: 1, Size: 3}},
+		wt.CalcModify([]byte("abcde"), []byte("ae")))
+	require.EqualValues(t,
+		[]wt.Modification{
+			{Data: []byte("X"), Offset: 1, Size: 1},
+			{Data: []byte("Y"), Offset: 31, Size: 1}},
+		wt.CalcModify(
+			[]byte("0123456789012345678901234567890123456789"),
+			[]byte("0X23456789012345678901234567890Y23456789")))
+
+	// Applying modifications must always produce new value.
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		oldValue := make([]byte, r.Intn(100))
+		r.Read(oldValue)
+		newValue := append([]byte{}, oldValue...)
+		for j := r.Intn(5); j > 0 && len(newValue) > 0; j-- {
+			newValue[r.Intn(len(newValue))] = byte(r.Intn(256))
+		}
+		if r.Intn(2) == 0 {
+			newValue = append(newValue, byte(r.Intn(256)))
+		}
+		v := applyModifications(oldValue, wt.CalcModify(oldValue, newValue))
+		require.True(t, bytes.Equal(newValue, v), "%x -> %x != %x", oldValue, newValue, v)
+	}
+}
+
+func applyModifications(value []byte, mods []wt.Modification) []byte {
+	for _, m := range mods {
+		r := append([]byte{}, value[:m.Offset]...)
+		r = append(r, m.Data...)
+		value = append(r, value[m.Offset+m.Size:]...)
+	}
+	return value
+}
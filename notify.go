@@ -0,0 +1,124 @@
+package wt
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotifierClosed is returned to TxCommitNotify waiters, if transaction commits after
+// connection has started closing.
+var ErrNotifierClosed = errors.New("connection closed before log sync")
+
+// TxCommitNotify performs WT_SESSION::commit_transaction call without waiting for the log
+// to be synced, and returns a channel that receives nil once log records of the transaction
+// are durable, or an error if syncing the log has failed. Returned channel receives exactly
+// one value.
+//
+// Commits from all sessions of the connection are synced together, using a single
+// WT_SESSION::log_flush call for all transactions that are waiting, which allows for
+// much higher throughput than committing each transaction with Sync: True.
+func (s *Session) TxCommitNotify() (<-chan error, error) {
+	// Notifier is set up before commit, so that returned error always means that
+	// transaction wasn't committed.
+	n, err := s.conn.syncNotifier()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TxCommit(TxCfg{Sync: False}); err != nil {
+		return nil, err
+	}
+	return n.wait(), nil
+}
+
+// syncNotifier syncs the log on behalf of TxCommitNotify waiters, using its own session.
+// Waiters are registered after their transactions commit, thus a log flush that starts
+// after waiter is registered is guaranteed to cover its log records.
+type syncNotifier struct {
+	s       *Session
+	wake    chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	pending []chan error
+}
+
+func (c *Connection) syncNotifier() (*syncNotifier, error) {
+	c.notifierMu.Lock()
+	defer c.notifierMu.Unlock()
+	if c.notifier != nil {
+		return c.notifier, nil
+	}
+	s, err := c.OpenSession()
+	if err != nil {
+		return nil, err
+	}
+	n := &syncNotifier{
+		s:       s,
+		wake:    make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go n.run()
+	c.notifier = n
+	return n, nil
+}
+
+func (c *Connection) stopSyncNotifier() {
+	c.notifierMu.Lock()
+	defer c.notifierMu.Unlock()
+	if c.notifier == nil {
+		return
+	}
+	close(c.notifier.closing)
+	<-c.notifier.done
+	c.notifier = nil
+}
+
+func (n *syncNotifier) wait() <-chan error {
+	ch := make(chan error, 1)
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		ch <- ErrNotifierClosed
+		return ch
+	}
+	n.pending = append(n.pending, ch)
+	n.mu.Unlock()
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+	return ch
+}
+
+func (n *syncNotifier) run() {
+	defer close(n.done)
+	defer n.s.Close()
+	for {
+		select {
+		case <-n.wake:
+			n.flush(false)
+		case <-n.closing:
+			n.flush(true)
+			return
+		}
+	}
+}
+
+// flush syncs the log and notifies all waiters that were registered before the sync.
+func (n *syncNotifier) flush(last bool) {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	n.closed = last
+	n.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	err := n.s.LogFlush(SyncOn)
+	for _, ch := range pending {
+		ch <- err
+	}
+}
@@ -0,0 +1,88 @@
+package wt_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestTransactionSync(t *testing.T) {
+	s := wttest.NewDB(t, wttest.DBOpts{Cfg: wt.ConnCfg{Log: "enabled"}}).Session
+
+	cc, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer cc.Close()
+	require.NoError(t, s.TxBegin())
+	require.NoError(t, cc.Insert([]byte("key1"), []byte("value1")))
+	require.Error(t, s.TransactionSync(time.Second)) // Can't be called inside a transaction.
+	require.NoError(t, s.TxCommit(wt.TxCfg{Sync: wt.False}))
+
+	require.NoError(t, s.LogFlush(wt.SyncBackground))
+	require.NoError(t, s.TransactionSync(10*time.Second))
+
+	// Background sync is done by a separate WiredTiger thread, thus it may or may not be
+	// done yet, right after LogFlush(SyncBackground) call.
+	require.NoError(t, s.TxBegin())
+	require.NoError(t, cc.Insert([]byte("key2"), []byte("value2")))
+	require.NoError(t, s.TxCommit(wt.TxCfg{Sync: wt.False}))
+	require.NoError(t, s.LogFlush(wt.SyncBackground))
+	if err := s.TransactionSync(0); err != nil {
+		require.True(t, errors.Is(err, wt.ErrTimedOut), err)
+		require.EqualValues(t, wt.ErrTimedOut, wt.ErrCode(err))
+	}
+	require.NoError(t, s.TransactionSync(10*time.Second))
+}
+
+func TestTxCommitNotify(t *testing.T) {
+	db := wttest.NewDB(t, wttest.DBOpts{Cfg: wt.ConnCfg{Log: "enabled"}})
+
+	nWriters := 4
+	nTxs := 50
+	var wg sync.WaitGroup
+	for w := 0; w < nWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			s, err := db.Conn.OpenSession()
+			require.NoError(t, err)
+			defer s.Close()
+			cc, err := s.OpenCursor("table:test_table")
+			require.NoError(t, err)
+			defer cc.Close()
+			var waits []<-chan error
+			for i := 0; i < nTxs; i++ {
+				require.NoError(t, s.TxBegin())
+				key := []byte(strconv.Itoa(w) + "-" + strconv.Itoa(i))
+				require.NoError(t, cc.Insert(key, key))
+				done, err := s.TxCommitNotify()
+				require.NoError(t, err)
+				waits = append(waits, done)
+			}
+			for _, done := range waits {
+				select {
+				case err := <-done:
+					require.NoError(t, err)
+				case <-time.After(10 * time.Second):
+					t.Error("timed out waiting for log sync")
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cc, err := db.Session.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer cc.Close()
+	count := 0
+	for cc.Next() == nil {
+		count++
+	}
+	require.Equal(t, nWriters*nTxs, count)
+}
@@ -0,0 +1,552 @@
+package wt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// Pack encodes `args` according to WiredTiger packing `format`, same as wiredtiger_struct_pack
+// call would. Packed data can be used as keys and values for data sources created with
+// matching key_format/value_format. Supported format characters are:
+//
+//	x       pad byte, no argument
+//	b, B    int8, uint8
+//	h, H    int16, uint16
+//	i, I    int32, uint32
+//	l, L    int32, uint32
+//	q, Q    int64, uint64
+//	r       uint64 record number
+//	t       uint8 bitfield, count specifies number of bits
+//	s       fixed length string, count specifies length
+//	S       NUL terminated string
+//	u       raw []byte
+//
+// Integer arguments can be of any Go integer type, as long as value fits. String arguments
+// can be either string or []byte.
+func Pack(format string, args ...interface{}) ([]byte, error) {
+	return AppendPack(nil, format, args...)
+}
+
+// AppendPack is same as Pack, except it appends packed data to `dst`. This allows reusing
+// buffers between calls.
+func AppendPack(dst []byte, format string, args ...interface{}) ([]byte, error) {
+	fields, err := parseFormat(format)
+	if err != nil {
+		return dst, err
+	}
+	argIdx := 0
+	for fIdx, f := range fields {
+		if f.typ == 'x' {
+			for i := 0; i < f.size; i++ {
+				dst = append(dst, 0)
+			}
+			continue
+		}
+		for i := 0; i < f.repeat; i++ {
+			if argIdx >= len(args) {
+				return dst, fmt.Errorf("not enough arguments for format: %q", format)
+			}
+			isLast := fIdx == len(fields)-1 && i == f.repeat-1
+			dst, err = packField(dst, f, isLast, args[argIdx])
+			if err != nil {
+				return dst, fmt.Errorf("argument %d: %w", argIdx, err)
+			}
+			argIdx++
+		}
+	}
+	if argIdx != len(args) {
+		return dst, fmt.Errorf("too many arguments for format: %q", format)
+	}
+	return dst, nil
+}
+
+// Unpack decodes `buf` according to WiredTiger packing `format`, same as wiredtiger_struct_unpack
+// call would. `ptrs` must be pointers to values of appropriate types, see Pack for details.
+// Pointers can be nil, to skip corresponding fields. Strings and byte slices are always copied
+// from `buf`, thus `buf` can be reused afterwards.
+func Unpack(format string, buf []byte, ptrs ...interface{}) error {
+	fields, err := parseFormat(format)
+	if err != nil {
+		return err
+	}
+	ptrIdx := 0
+	for fIdx, f := range fields {
+		if f.typ == 'x' {
+			if len(buf) < f.size {
+				return errPackShortBuffer
+			}
+			buf = buf[f.size:]
+			continue
+		}
+		for i := 0; i < f.repeat; i++ {
+			if ptrIdx >= len(ptrs) {
+				return fmt.Errorf("not enough arguments for format: %q", format)
+			}
+			isLast := fIdx == len(fields)-1 && i == f.repeat-1
+			buf, err = unpackField(buf, f, isLast, ptrs[ptrIdx])
+			if err != nil {
+				return fmt.Errorf("argument %d: %w", ptrIdx, err)
+			}
+			ptrIdx++
+		}
+	}
+	if ptrIdx != len(ptrs) {
+		return fmt.Errorf("too many arguments for format: %q", format)
+	}
+	return nil
+}
+
+var errPackShortBuffer = errors.New("buffer too short for format")
+
+type formatField struct {
+	typ    byte
+	size   int  // Size for 's', 'u' and 't' types, or number of pad bytes for 'x' type.
+	sized  bool // True if size was explicitly set.
+	repeat int  // Number of values of this field.
+}
+
+func parseFormat(format string) ([]formatField, error) {
+	if len(format) > 0 {
+		switch format[0] {
+		case '@', '<', '>', '!', '.':
+			format = format[1:]
+		}
+	}
+	var fields []formatField
+	for i := 0; i < len(format); i++ {
+		count, sized := 0, false
+		for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			count = count*10 + int(format[i]-'0')
+			sized = true
+		}
+		if i >= len(format) {
+			return nil, fmt.Errorf("invalid format: %q", format)
+		}
+		f := formatField{typ: format[i], repeat: 1}
+		switch f.typ {
+		case 'x':
+			f.size = 1
+			if sized {
+				f.size = count
+			}
+		case 's':
+			f.size = 1
+			f.sized = true
+			if sized {
+				f.size = count
+			}
+		case 't':
+			f.size = 1
+			f.sized = true
+			if sized {
+				f.size = count
+			}
+			if f.size < 1 || f.size > 8 {
+				return nil, fmt.Errorf("invalid bitfield size: %q", format)
+			}
+		case 'S', 'u':
+			f.size, f.sized = count, sized
+		case 'b', 'B', 'h', 'H', 'i', 'I', 'l', 'L', 'q', 'Q', 'r':
+			if sized {
+				f.repeat = count
+			}
+		default:
+			return nil, fmt.Errorf("invalid format character %q: %q", f.typ, format)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func packField(dst []byte, f formatField, isLast bool, arg interface{}) ([]byte, error) {
+	switch f.typ {
+	case 'b':
+		v, err := argInt(arg, math.MinInt8, math.MaxInt8)
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, byte(v+0x80)), nil // Flip sign bit to maintain ordering.
+	case 'B':
+		v, err := argUint(arg, math.MaxUint8)
+		return append(dst, byte(v)), err
+	case 't':
+		v, err := argUint(arg, 1<<uint(f.size)-1)
+		return append(dst, byte(v)), err
+	case 'h', 'i', 'l', 'q':
+		v, err := argInt(arg, intMin(f.typ), intMax(f.typ))
+		if err != nil {
+			return dst, err
+		}
+		return packInt(dst, v), nil
+	case 'H', 'I', 'L', 'Q', 'r':
+		v, err := argUint(arg, uintMax(f.typ))
+		if err != nil {
+			return dst, err
+		}
+		return packUint(dst, v), nil
+	case 's', 'S':
+		v, err := argBytes(arg)
+		if err != nil {
+			return dst, err
+		}
+		if !f.sized {
+			if bytes.IndexByte(v, 0) >= 0 {
+				return dst, fmt.Errorf("string contains NUL byte")
+			}
+			dst = append(dst, v...)
+			return append(dst, 0), nil
+		}
+		if len(v) > f.size {
+			v = v[:f.size]
+		}
+		dst = append(dst, v...)
+		for i := len(v); i < f.size; i++ {
+			dst = append(dst, 0)
+		}
+		return dst, nil
+	case 'u':
+		v, err := argBytes(arg)
+		if err != nil {
+			return dst, err
+		}
+		if f.sized {
+			if len(v) != f.size {
+				return dst, fmt.Errorf("expected %d bytes, got: %d", f.size, len(v))
+			}
+		} else if !isLast {
+			dst = packUint(dst, uint64(len(v)))
+		}
+		return append(dst, v...), nil
+	}
+	panic(fmt.Sprintf("unreachable: %q", f.typ))
+}
+
+func unpackField(buf []byte, f formatField, isLast bool, ptr interface{}) ([]byte, error) {
+	switch f.typ {
+	case 'b', 'B', 't':
+		if len(buf) < 1 {
+			return buf, errPackShortBuffer
+		}
+		if f.typ == 'b' {
+			return buf[1:], setInt(ptr, int64(buf[0])-0x80)
+		}
+		return buf[1:], setUint(ptr, uint64(buf[0]))
+	case 'h', 'i', 'l', 'q':
+		v, buf, err := unpackInt(buf)
+		if err != nil {
+			return buf, err
+		}
+		return buf, setInt(ptr, v)
+	case 'H', 'I', 'L', 'Q', 'r':
+		v, buf, err := unpackUint(buf)
+		if err != nil {
+			return buf, err
+		}
+		return buf, setUint(ptr, v)
+	case 's', 'S':
+		var v []byte
+		if !f.sized {
+			idx := bytes.IndexByte(buf, 0)
+			if idx < 0 {
+				return buf, errPackShortBuffer
+			}
+			v, buf = buf[:idx], buf[idx+1:]
+		} else {
+			if len(buf) < f.size {
+				return buf, errPackShortBuffer
+			}
+			v, buf = buf[:f.size], buf[f.size:]
+			if idx := bytes.IndexByte(v, 0); idx >= 0 {
+				v = v[:idx]
+			}
+		}
+		return buf, setBytes(ptr, v)
+	case 'u':
+		size := len(buf)
+		if f.sized {
+			size = f.size
+		} else if !isLast {
+			v, rest, err := unpackUint(buf)
+			if err != nil {
+				return buf, err
+			}
+			if v > uint64(len(rest)) {
+				return buf, errPackShortBuffer
+			}
+			size, buf = int(v), rest
+		}
+		if len(buf) < size {
+			return buf, errPackShortBuffer
+		}
+		return buf[size:], setBytes(ptr, buf[:size])
+	}
+	panic(fmt.Sprintf("unreachable: %q", f.typ))
+}
+
+func intMin(typ byte) int64 {
+	switch typ {
+	case 'h':
+		return math.MinInt16
+	case 'i', 'l':
+		return math.MinInt32
+	}
+	return math.MinInt64
+}
+
+func intMax(typ byte) int64 {
+	switch typ {
+	case 'h':
+		return math.MaxInt16
+	case 'i', 'l':
+		return math.MaxInt32
+	}
+	return math.MaxInt64
+}
+
+func uintMax(typ byte) uint64 {
+	switch typ {
+	case 'H':
+		return math.MaxUint16
+	case 'I', 'L':
+		return math.MaxUint32
+	}
+	return math.MaxUint64
+}
+
+func argInt(arg interface{}, min, max int64) (int64, error) {
+	v := reflect.ValueOf(arg)
+	var r int64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value out of range: %d", v.Uint())
+		}
+		r = int64(v.Uint())
+	default:
+		return 0, fmt.Errorf("expected integer, got: %T", arg)
+	}
+	if r < min || r > max {
+		return 0, fmt.Errorf("value out of range: %d", r)
+	}
+	return r, nil
+}
+
+func argUint(arg interface{}, max uint64) (uint64, error) {
+	v := reflect.ValueOf(arg)
+	var r uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("value out of range: %d", v.Int())
+		}
+		r = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		r = v.Uint()
+	default:
+		return 0, fmt.Errorf("expected integer, got: %T", arg)
+	}
+	if r > max {
+		return 0, fmt.Errorf("value out of range: %d", r)
+	}
+	return r, nil
+}
+
+func argBytes(arg interface{}) ([]byte, error) {
+	switch v := arg.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("expected string or []byte, got: %T", arg)
+}
+
+func setInt(ptr interface{}, v int64) error {
+	if ptr == nil {
+		return nil
+	}
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		return fmt.Errorf("expected pointer to integer, got: %T", ptr)
+	}
+	e := p.Elem()
+	switch e.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if e.OverflowInt(v) {
+			return fmt.Errorf("value overflows %T: %d", ptr, v)
+		}
+		e.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v < 0 || e.OverflowUint(uint64(v)) {
+			return fmt.Errorf("value overflows %T: %d", ptr, v)
+		}
+		e.SetUint(uint64(v))
+	default:
+		return fmt.Errorf("expected pointer to integer, got: %T", ptr)
+	}
+	return nil
+}
+
+func setUint(ptr interface{}, v uint64) error {
+	if ptr == nil {
+		return nil
+	}
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		return fmt.Errorf("expected pointer to integer, got: %T", ptr)
+	}
+	e := p.Elem()
+	switch e.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v > math.MaxInt64 || e.OverflowInt(int64(v)) {
+			return fmt.Errorf("value overflows %T: %d", ptr, v)
+		}
+		e.SetInt(int64(v))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if e.OverflowUint(v) {
+			return fmt.Errorf("value overflows %T: %d", ptr, v)
+		}
+		e.SetUint(v)
+	default:
+		return fmt.Errorf("expected pointer to integer, got: %T", ptr)
+	}
+	return nil
+}
+
+func setBytes(ptr interface{}, v []byte) error {
+	switch p := ptr.(type) {
+	case nil:
+	case *[]byte:
+		*p = copyBuffer(v)
+	case *string:
+		*p = string(v)
+	default:
+		return fmt.Errorf("expected *string or *[]byte, got: %T", ptr)
+	}
+	return nil
+}
+
+// Variable length integer encoding, mirrors WiredTiger's intpack.i. Encoding preserves
+// ordering of integers, when compared as bytes.
+const (
+	negMultiMarker = 0x10
+	neg2ByteMarker = 0x20
+	neg1ByteMarker = 0x40
+	pos1ByteMarker = 0x80
+	pos2ByteMarker = 0xc0
+	posMultiMarker = 0xe0
+
+	neg1ByteMin = -(1 << 6)
+	neg2ByteMin = -(1 << 13) + neg1ByteMin
+	pos1ByteMax = (1 << 6) - 1
+	pos2ByteMax = (1 << 13) + pos1ByteMax
+)
+
+func packUint(dst []byte, x uint64) []byte {
+	switch {
+	case x <= pos1ByteMax:
+		return append(dst, pos1ByteMarker|byte(x&0x3f))
+	case x <= pos2ByteMax:
+		x -= pos1ByteMax + 1
+		return append(dst, pos2ByteMarker|byte((x>>8)&0x1f), byte(x))
+	case x == pos2ByteMax+1:
+		// Special case that could be stored with a single byte, but it is stored with
+		// two, so that encoding doesn't get shorter for this one value.
+		return append(dst, posMultiMarker|0x1, 0)
+	}
+	x -= pos2ByteMax + 1
+	size := 8 - bits.LeadingZeros64(x)/8
+	dst = append(dst, posMultiMarker|byte(size))
+	return appendBigEndian(dst, x, size)
+}
+
+func packInt(dst []byte, x int64) []byte {
+	switch {
+	case x < neg2ByteMin:
+		// Number of leading 0xff bytes is stored instead of size to maintain ordering.
+		lz := bits.LeadingZeros64(^uint64(x)) / 8
+		dst = append(dst, negMultiMarker|byte(lz))
+		return appendBigEndian(dst, uint64(x), 8-lz)
+	case x < neg1ByteMin:
+		x -= neg2ByteMin
+		return append(dst, neg2ByteMarker|byte((x>>8)&0x1f), byte(x))
+	case x < 0:
+		x -= neg1ByteMin
+		return append(dst, neg1ByteMarker|byte(x&0x3f))
+	}
+	return packUint(dst, uint64(x))
+}
+
+func appendBigEndian(dst []byte, x uint64, size int) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	return append(dst, b[8-size:]...)
+}
+
+func unpackUint(buf []byte) (uint64, []byte, error) {
+	if len(buf) < 1 {
+		return 0, buf, errPackShortBuffer
+	}
+	switch buf[0] & 0xf0 {
+	case pos1ByteMarker, pos1ByteMarker | 0x10, pos1ByteMarker | 0x20, pos1ByteMarker | 0x30:
+		return uint64(buf[0] & 0x3f), buf[1:], nil
+	case pos2ByteMarker, pos2ByteMarker | 0x10:
+		if len(buf) < 2 {
+			return 0, buf, errPackShortBuffer
+		}
+		x := uint64(buf[0]&0x1f)<<8 | uint64(buf[1])
+		return x + pos1ByteMax + 1, buf[2:], nil
+	case posMultiMarker:
+		size := int(buf[0] & 0xf)
+		if size > 8 || len(buf) < 1+size {
+			return 0, buf, errPackShortBuffer
+		}
+		var x uint64
+		for _, b := range buf[1 : 1+size] {
+			x = x<<8 | uint64(b)
+		}
+		return x + pos2ByteMax + 1, buf[1+size:], nil
+	}
+	return 0, buf, fmt.Errorf("invalid unsigned integer encoding: 0x%x", buf[0])
+}
+
+func unpackInt(buf []byte) (int64, []byte, error) {
+	if len(buf) < 1 {
+		return 0, buf, errPackShortBuffer
+	}
+	switch buf[0] & 0xf0 {
+	case negMultiMarker:
+		size := 8 - int(buf[0]&0xf)
+		if size < 0 || len(buf) < 1+size {
+			return 0, buf, errPackShortBuffer
+		}
+		x := ^uint64(0)
+		for _, b := range buf[1 : 1+size] {
+			x = x<<8 | uint64(b)
+		}
+		return int64(x), buf[1+size:], nil
+	case neg2ByteMarker, neg2ByteMarker | 0x10:
+		if len(buf) < 2 {
+			return 0, buf, errPackShortBuffer
+		}
+		x := int64(buf[0]&0x1f)<<8 | int64(buf[1])
+		return x + neg2ByteMin, buf[2:], nil
+	case neg1ByteMarker, neg1ByteMarker | 0x10, neg1ByteMarker | 0x20, neg1ByteMarker | 0x30:
+		return int64(buf[0]&0x3f) + neg1ByteMin, buf[1:], nil
+	}
+	x, rest, err := unpackUint(buf)
+	if err != nil {
+		return 0, buf, err
+	}
+	if x > math.MaxInt64 {
+		return 0, buf, fmt.Errorf("signed integer overflow: %d", x)
+	}
+	return int64(x), rest, nil
+}
@@ -0,0 +1,146 @@
+package wt
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPack(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		args   []interface{}
+		packed []byte
+	}{
+		{"q", []interface{}{0}, []byte{0x80}},
+		{"q", []interface{}{63}, []byte{0xbf}},
+		{"q", []interface{}{64}, []byte{0xc0, 0x00}},
+		{"q", []interface{}{8255}, []byte{0xdf, 0xff}},
+		{"q", []interface{}{8256}, []byte{0xe1, 0x00}},
+		{"q", []interface{}{8257}, []byte{0xe1, 0x01}},
+		{"q", []interface{}{-1}, []byte{0x7f}},
+		{"q", []interface{}{-64}, []byte{0x40}},
+		{"q", []interface{}{-65}, []byte{0x3f, 0xff}},
+		{"q", []interface{}{-8256}, []byte{0x20, 0x00}},
+		{"q", []interface{}{-8257}, []byte{0x16, 0xdf, 0xbf}},
+		{"Q", []interface{}{uint64(math.MaxUint64)},
+			[]byte{0xe8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xdf, 0xbf}},
+		{"r", []interface{}{1}, []byte{0x81}},
+		{"b", []interface{}{-1}, []byte{0x7f}},
+		{"B", []interface{}{200}, []byte{200}},
+		{"3t", []interface{}{5}, []byte{5}},
+		{"2q", []interface{}{1, 2}, []byte{0x81, 0x82}},
+		{"S", []interface{}{"abc"}, []byte("abc\x00")},
+		{"Si", []interface{}{"abc", 1}, []byte("abc\x00\x81")},
+		{"5s", []interface{}{"abc"}, []byte("abc\x00\x00")},
+		{"2s", []interface{}{"abc"}, []byte("ab")},
+		{"s", []interface{}{"abc"}, []byte("a")},
+		{"u", []interface{}{[]byte("ab")}, []byte("ab")},
+		{"uq", []interface{}{[]byte("ab"), 1}, []byte("\x82ab\x81")},
+		{"2u", []interface{}{[]byte("ab")}, []byte("ab")},
+		{"qxu", []interface{}{1, "ab"}, []byte("\x81\x00ab")},
+		{">Su", []interface{}{"k", []byte{}}, []byte("k\x00")},
+	} {
+		packed, err := Pack(tc.format, tc.args...)
+		require.NoError(t, err, tc.format)
+		require.EqualValues(t, tc.packed, packed, tc.format)
+
+		ptrs := make([]interface{}, len(tc.args))
+		for idx, arg := range tc.args {
+			switch arg.(type) {
+			case string:
+				ptrs[idx] = new(string)
+			case []byte:
+				ptrs[idx] = new([]byte)
+			default:
+				ptrs[idx] = new(int64)
+				if tc.format == "Q" {
+					ptrs[idx] = new(uint64)
+				}
+			}
+		}
+		err = Unpack(tc.format, packed, ptrs...)
+		require.NoError(t, err, tc.format)
+		packed2, err := Pack(tc.format, derefAll(ptrs)...)
+		require.NoError(t, err, tc.format)
+		require.EqualValues(t, packed, packed2, tc.format)
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	_, err := Pack("q")
+	require.Error(t, err)
+	_, err = Pack("q", 1, 2)
+	require.Error(t, err)
+	_, err = Pack("q", "abc")
+	require.Error(t, err)
+	_, err = Pack("i", int64(math.MaxInt32)+1)
+	require.Error(t, err)
+	_, err = Pack("Q", -1)
+	require.Error(t, err)
+	_, err = Pack("S", "a\x00b")
+	require.Error(t, err)
+	_, err = Pack("z", 1)
+	require.Error(t, err)
+	_, err = Pack("2u", []byte("abc"))
+	require.Error(t, err)
+
+	var v int8
+	err = Unpack("q", []byte{0xc1, 0x00}, &v)
+	require.Error(t, err)
+	err = Unpack("q", []byte{0xc0}, &v)
+	require.Error(t, err)
+	err = Unpack("S", []byte("abc"), new(string))
+	require.Error(t, err)
+}
+
+func TestPackOrdering(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	randInt := func() int64 {
+		// Spread values over all encoding lengths.
+		return r.Int63n(math.MaxInt64) >> uint(r.Intn(64)) * int64(1-2*r.Intn(2))
+	}
+	for i := 0; i < 10000; i++ {
+		a, b := randInt(), randInt()
+		pa, err := Pack("q", a)
+		require.NoError(t, err)
+		pb, err := Pack("q", b)
+		require.NoError(t, err)
+		require.EqualValues(t, cmpInt64(a, b), bytes.Compare(pa, pb), "%d vs %d", a, b)
+
+		var ua, ub int64
+		require.NoError(t, Unpack("q", pa, &ua))
+		require.NoError(t, Unpack("q", pb, &ub))
+		require.EqualValues(t, a, ua)
+		require.EqualValues(t, b, ub)
+	}
+}
+
+func cmpInt64(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func derefAll(ptrs []interface{}) []interface{} {
+	r := make([]interface{}, len(ptrs))
+	for idx, p := range ptrs {
+		switch p := p.(type) {
+		case *string:
+			r[idx] = *p
+		case *[]byte:
+			r[idx] = *p
+		case *int64:
+			r[idx] = *p
+		case *uint64:
+			r[idx] = *p
+		}
+	}
+	return r
+}
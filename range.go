@@ -0,0 +1,286 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include "callbacks.h"
+
+// WT_CURSOR::bound is only available starting with WiredTiger 11.1.
+#if WIREDTIGER_VERSION_MAJOR > 11 || \
+	(WIREDTIGER_VERSION_MAJOR == 11 && WIREDTIGER_VERSION_MINOR >= 1)
+#define WT_HAS_CURSOR_BOUND 1
+#endif
+
+static int _range_compare(
+	const void *a, size_t a_size,
+	const void *b, size_t b_size) {
+	size_t n = a_size < b_size ? a_size : b_size;
+	int c = n > 0 ? memcmp(a, b, n) : 0;
+	if (c != 0) {
+		return c;
+	}
+	return (a_size > b_size) - (a_size < b_size);
+}
+
+// Sets bounds using WT_CURSOR::bound call, if it is supported. Returns 1 in *boundedp if
+// bounds were set.
+int wt_cursor_range_bound(
+	WT_CURSOR *cursor,
+	const void *lower, size_t lower_size,
+	const void *upper, size_t upper_size, int upper_inclusive,
+	int use_bound, int *boundedp) {
+	*boundedp = 0;
+	if (!use_bound) {
+		return 0;
+	}
+#ifdef WT_HAS_CURSOR_BOUND
+	WT_ITEM item;
+	int r;
+	if (lower_size > 0) {
+		item.data = lower;
+		item.size = lower_size;
+		cursor->set_key(cursor, &item);
+		r = cursor->bound(cursor, "action=set,bound=lower,inclusive=true");
+		if (r != 0) {
+			return r;
+		}
+	}
+	if (upper_size > 0) {
+		item.data = upper;
+		item.size = upper_size;
+		cursor->set_key(cursor, &item);
+		r = cursor->bound(cursor,
+			upper_inclusive ?
+			"action=set,bound=upper,inclusive=true" :
+			"action=set,bound=upper,inclusive=false");
+		if (r != 0) {
+			return r;
+		}
+	}
+	*boundedp = 1;
+#endif
+	return 0;
+}
+
+// Moves cursor to the next key/value pair within bounds. If cursor isn't positioned yet and
+// bounds weren't set using WT_CURSOR::bound call, positions it using WT_CURSOR::search_near.
+// Once cursor moves out of bounds, it is reset and WT_NOTFOUND is returned.
+wt_cursor_kv wt_cursor_range_step(
+	WT_CURSOR *cursor, int reverse, int positioned, int bounded,
+	const void *lower, size_t lower_size,
+	const void *upper, size_t upper_size, int upper_inclusive) {
+	wt_cursor_kv kv = {0};
+	const void *start = reverse ? upper : lower;
+	size_t start_size = reverse ? upper_size : lower_size;
+	if (!positioned && !bounded && start_size > 0) {
+		WT_ITEM item;
+		int exact;
+		item.data = start;
+		item.size = start_size;
+		cursor->set_key(cursor, &item);
+		kv.r = cursor->search_near(cursor, &exact);
+		if (kv.r == 0) {
+			if (!reverse && exact < 0) {
+				kv.r = cursor->next(cursor);
+			} else if (reverse && (exact > 0 || (exact == 0 && !upper_inclusive))) {
+				kv.r = cursor->prev(cursor);
+			}
+		}
+	} else {
+		kv.r = reverse ? cursor->prev(cursor) : cursor->next(cursor);
+	}
+	if (kv.r != 0) {
+		return kv;
+	}
+	WT_ITEM key, value;
+	if ((kv.r = cursor->get_key(cursor, &key)) != 0) {
+		return kv;
+	}
+	if (!bounded) {
+		int out = 0;
+		if (!reverse && upper_size > 0) {
+			int c = _range_compare(key.data, key.size, upper, upper_size);
+			out = c > 0 || (c == 0 && !upper_inclusive);
+		} else if (reverse && lower_size > 0) {
+			out = _range_compare(key.data, key.size, lower, lower_size) < 0;
+		}
+		if (out) {
+			kv.r = cursor->reset(cursor);
+			if (kv.r == 0) {
+				kv.r = WT_NOTFOUND;
+			}
+			return kv;
+		}
+	}
+	if ((kv.r = cursor->get_value(cursor, &value)) != 0) {
+		return kv;
+	}
+	kv.key = key.data;
+	kv.key_size = key.size;
+	kv.value = value.data;
+	kv.value_size = value.size;
+	return kv;
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"unsafe"
+)
+
+// RangeOpts describes range of keys to iterate over with Cursor.Range call.
+type RangeOpts struct {
+	// Start is an inclusive lower bound. Empty Start means no lower bound.
+	Start []byte
+	// End is an upper bound, exclusive unless Inclusive is set. Empty End means
+	// no upper bound.
+	End []byte
+	// Prefix limits range to keys that start with Prefix. Can be combined with
+	// Start and End bounds.
+	Prefix []byte
+	// Reverse iterates from the largest key to the smallest.
+	Reverse bool
+	// Inclusive makes End bound inclusive.
+	Inclusive bool
+}
+
+// RangeIter iterates over key/value pairs in a range. Must be closed with Close call
+// once it is no longer needed, to release the cursor for other operations.
+type RangeIter struct {
+	c              *Cursor
+	reverse        bool
+	lower          []byte
+	upper          []byte
+	upperInclusive bool
+	positioned     bool
+	bounded        C.int
+	done           bool
+	key            []byte
+	value          []byte
+	err            error
+}
+
+// rangeUseBound can be disabled in tests, to exercise bounds that are enforced by the
+// iterator itself with WiredTiger versions that support WT_CURSOR::bound call.
+var rangeUseBound = true
+
+// Range resets the cursor and returns iterator over key/value pairs in the range described
+// by `opts`. Bounds are enforced using WT_CURSOR::bound call if WiredTiger library supports it,
+// i.e. for versions 11.1 and later, otherwise they are enforced by the iterator itself. Either
+// way, iterating doesn't allocate any memory. Cursor must not be used for other operations
+// until iterator is closed.
+func (c *Cursor) Range(opts RangeOpts) *RangeIter {
+	it := &RangeIter{c: c, reverse: opts.Reverse}
+	it.lower = opts.Start
+	it.upper = opts.End
+	it.upperInclusive = opts.Inclusive && len(opts.End) > 0
+	if len(opts.Prefix) > 0 {
+		if bytes.Compare(opts.Prefix, it.lower) > 0 {
+			it.lower = opts.Prefix
+		}
+		if prefixEnd := prefixUpperBound(opts.Prefix); prefixEnd != nil &&
+			(len(it.upper) == 0 || bytes.Compare(prefixEnd, it.upper) <= 0) {
+			it.upper = prefixEnd
+			it.upperInclusive = false
+		}
+	}
+	if len(it.lower) > 0 && len(it.upper) > 0 {
+		cmp := bytes.Compare(it.lower, it.upper)
+		if cmp > 0 || (cmp == 0 && !it.upperInclusive) {
+			it.done = true // Range is empty.
+		}
+	}
+	if it.err = c.Reset(); it.err != nil || it.done {
+		return it
+	}
+	lowerP, lowerSize := bytesC(it.lower)
+	upperP, upperSize := bytesC(it.upper)
+	r := C.wt_cursor_range_bound(
+		c.c, lowerP, lowerSize, upperP, upperSize, boolC(it.upperInclusive),
+		boolC(rangeUseBound), &it.bounded)
+	it.err = c.cursorError(r, "bound", nil)
+	return it
+}
+
+// Next moves iterator to the next key/value pair in the range. Returns false when there are
+// no more pairs left, or if an error occurs. Use Err call to distinguish between the two.
+func (it *RangeIter) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	lowerP, lowerSize := bytesC(it.lower)
+	upperP, upperSize := bytesC(it.upper)
+	kv := C.wt_cursor_range_step(
+		it.c.c, boolC(it.reverse), boolC(it.positioned), it.bounded,
+		lowerP, lowerSize, upperP, upperSize, boolC(it.upperInclusive))
+	it.positioned = true
+	if kv.r != 0 {
+		it.key, it.value = nil, nil
+		it.done = true
+		if ErrorCode(kv.r) != ErrNotFound {
+			it.err = it.c.cursorError(kv.r, moveOp(boolC(it.reverse)), nil)
+		}
+		return false
+	}
+	it.key = (*[goArrayMaxLen]byte)(kv.key)[:kv.key_size:kv.key_size]
+	it.value = nil
+	if kv.value_size > 0 {
+		it.value = (*[goArrayMaxLen]byte)(kv.value)[:kv.value_size:kv.value_size]
+	}
+	return true
+}
+
+// Key returns key of the current pair. Similar to Cursor.UnsafeKey, returned slice points
+// to `C` memory and it is only valid until next call to the iterator.
+func (it *RangeIter) Key() []byte {
+	return it.key
+}
+
+// Value returns value of the current pair. Similar to Cursor.UnsafeValue, returned slice points
+// to `C` memory and it is only valid until next call to the iterator.
+func (it *RangeIter) Value() []byte {
+	return it.value
+}
+
+// Err returns error that stopped the iteration, if any. Reaching the end of the
+// range is not an error.
+func (it *RangeIter) Err() error {
+	return it.err
+}
+
+// Close resets the cursor, clearing its bounds. Cursor itself stays open and can be
+// used for other operations.
+func (it *RangeIter) Close() error {
+	it.done = true
+	it.key, it.value = nil, nil
+	return it.c.Reset()
+}
+
+// prefixUpperBound returns smallest key that is larger than all keys starting with
+// `prefix`. Returns nil, if there is no such key.
+func prefixUpperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			r := make([]byte, i+1)
+			copy(r, prefix)
+			r[i]++
+			return r
+		}
+	}
+	return nil
+}
+
+func bytesC(b []byte) (unsafe.Pointer, C.size_t) {
+	if len(b) == 0 {
+		return nil, 0
+	}
+	return unsafe.Pointer(&b[0]), C.size_t(len(b))
+}
+
+func boolC(v bool) C.int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package wt_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestCursorRange(t *testing.T) {
+	t.Run("bound", testCursorRange)
+	t.Run("manual", func(t *testing.T) {
+		defer wt.SetRangeUseBound(false)()
+		testCursorRange(t)
+	})
+}
+
+func testCursorRange(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+	for _, prefix := range []string{"a", "b", "c"} {
+		for i := 0; i < 5; i++ {
+			err := c.Insert([]byte(prefix+strconv.Itoa(i)), []byte("value"+strconv.Itoa(i)))
+			require.NoError(t, err)
+		}
+	}
+
+	readRange := func(opts wt.RangeOpts) []string {
+		it := c.Range(opts)
+		defer func() { require.NoError(t, it.Close()) }()
+		var keys []string
+		for it.Next() {
+			require.EqualValues(t, "value", string(it.Value()[:5]))
+			keys = append(keys, string(it.Key()))
+		}
+		require.NoError(t, it.Err())
+		return keys
+	}
+
+	require.Len(t, readRange(wt.RangeOpts{}), 15)
+	require.Len(t, readRange(wt.RangeOpts{Reverse: true}), 15)
+	require.EqualValues(t,
+		[]string{"a3", "a4", "b0"},
+		readRange(wt.RangeOpts{Start: []byte("a3"), End: []byte("b1")}))
+	require.EqualValues(t,
+		[]string{"a3", "a4", "b0", "b1"},
+		readRange(wt.RangeOpts{Start: []byte("a3"), End: []byte("b1"), Inclusive: true}))
+	require.EqualValues(t,
+		[]string{"b1", "b0", "a4", "a3"},
+		readRange(wt.RangeOpts{Start: []byte("a3"), End: []byte("b1"), Inclusive: true, Reverse: true}))
+	require.EqualValues(t,
+		[]string{"b0", "a4", "a3"},
+		readRange(wt.RangeOpts{Start: []byte("a3"), End: []byte("b1"), Reverse: true}))
+	// Bounds that don't match existing keys.
+	require.EqualValues(t,
+		[]string{"a3", "a4"},
+		readRange(wt.RangeOpts{Start: []byte("a25"), End: []byte("a9")}))
+	require.EqualValues(t,
+		[]string{"a4", "a3"},
+		readRange(wt.RangeOpts{Start: []byte("a25"), End: []byte("a9"), Reverse: true}))
+
+	require.EqualValues(t,
+		[]string{"b0", "b1", "b2", "b3", "b4"},
+		readRange(wt.RangeOpts{Prefix: []byte("b")}))
+	require.EqualValues(t,
+		[]string{"b4", "b3", "b2", "b1", "b0"},
+		readRange(wt.RangeOpts{Prefix: []byte("b"), Reverse: true}))
+	require.EqualValues(t,
+		[]string{"b2", "b3"},
+		readRange(wt.RangeOpts{Prefix: []byte("b"), Start: []byte("b2"), End: []byte("b3"), Inclusive: true}))
+	require.EqualValues(t,
+		[]string{"c0", "c1"},
+		readRange(wt.RangeOpts{Prefix: []byte("c"), Start: []byte("a"), End: []byte("c2")}))
+
+	require.Len(t, readRange(wt.RangeOpts{Prefix: []byte("d")}), 0)
+	require.Len(t, readRange(wt.RangeOpts{Prefix: []byte("d"), Reverse: true}), 0)
+	require.Len(t, readRange(wt.RangeOpts{Start: []byte("b"), End: []byte("a")}), 0)
+	require.Len(t, readRange(wt.RangeOpts{Start: []byte("b1"), End: []byte("b1")}), 0)
+	require.EqualValues(t,
+		[]string{"b1"},
+		readRange(wt.RangeOpts{Start: []byte("b1"), End: []byte("b1"), Inclusive: true}))
+
+	// Cursor must be usable for other operations once iterator is closed.
+	v, err := c.ReadValue([]byte("a1"))
+	require.NoError(t, err)
+	require.EqualValues(t, "value1", string(v))
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	require.EqualValues(t, []byte("b"), wt.PrefixUpperBound([]byte("a")))
+	require.EqualValues(t, []byte("ac"), wt.PrefixUpperBound([]byte("ab")))
+	require.EqualValues(t, []byte("b"), wt.PrefixUpperBound([]byte("a\xff\xff")))
+	require.Nil(t, wt.PrefixUpperBound([]byte("\xff\xff")))
+}
@@ -0,0 +1,88 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include <wiredtiger.h>
+
+typedef struct {
+	int r;
+	uint8_t key[16];
+	size_t key_size;
+} wt_cursor_append_result;
+
+// Inserts value using cursor opened with 'append' config and returns packed
+// record number that was allocated for it. Cursor is reset afterwards.
+wt_cursor_append_result wt_cursor_append(
+	WT_CURSOR *cursor,
+	const void *value, size_t value_size) {
+	wt_cursor_append_result res = {0};
+	WT_ITEM item;
+	item.data = value;
+	item.size = value_size;
+	cursor->set_value(cursor, &item);
+	if ((res.r = cursor->insert(cursor)) != 0) {
+		return res;
+	}
+	if ((res.r = cursor->get_key(cursor, &item)) != 0) {
+		return res;
+	}
+	if (item.size > sizeof(res.key)) {
+		res.r = WT_ERROR;
+		return res;
+	}
+	memcpy(res.key, item.data, item.size);
+	res.key_size = item.size;
+	res.r = cursor->reset(cursor);
+	return res;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// PackRecno packs record number into raw key, for use with column-store data sources
+// created with 'key_format=r'.
+func PackRecno(recno uint64) []byte {
+	return packUint(nil, recno)
+}
+
+// UnpackRecno unpacks record number from raw key of a column-store data source.
+func UnpackRecno(key []byte) (uint64, error) {
+	recno, _, err := unpackUint(key)
+	return recno, err
+}
+
+// Append inserts value into a column-store data source, allocating new record number
+// for it. Cursor must be opened with 'append' config. Returns record number that was
+// allocated. Cursor is reset after this call.
+func (c *Cursor) Append(value []byte) (uint64, error) {
+	var valueP unsafe.Pointer
+	if len(value) > 0 {
+		valueP = unsafe.Pointer(&value[0])
+	}
+	res := C.wt_cursor_append(c.c, valueP, C.size_t(len(value)))
+	if res.r != 0 {
+		return 0, c.cursorError(res.r, "insert", nil)
+	}
+	key := (*[16]byte)(unsafe.Pointer(&res.key[0]))[:res.key_size]
+	return UnpackRecno(key)
+}
+
+// SearchRecno performs WT_CURSOR::search call for a record number in a
+// column-store data source.
+func (c *Cursor) SearchRecno(recno uint64) error {
+	return c.Search(PackRecno(recno))
+}
+
+// RecnoKey returns record number that cursor is pointing to in a column-store
+// data source.
+func (c *Cursor) RecnoKey() (uint64, error) {
+	k, err := c.UnsafeKey()
+	if err != nil {
+		return 0, err
+	}
+	return UnpackRecno(k)
+}
@@ -0,0 +1,77 @@
+package wt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestRecnoTable(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	err := s.Create("table:log", wt.DataSourceCfg{KeyFormat: "r", ValueFormat: "u"})
+	require.NoError(t, err)
+
+	c, err := s.OpenCursor("table:log", wt.CursorCfg{Append: wt.True})
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 1; i <= 100; i++ {
+		recno, err := c.Append([]byte("entry"))
+		require.NoError(t, err)
+		require.EqualValues(t, i, recno)
+	}
+
+	err = c.SearchRecno(70)
+	require.NoError(t, err)
+	recno, err := c.RecnoKey()
+	require.NoError(t, err)
+	require.EqualValues(t, 70, recno)
+	require.NoError(t, c.Next())
+	recno, err = c.RecnoKey()
+	require.NoError(t, err)
+	require.EqualValues(t, 71, recno)
+
+	err = c.UpdateValue(wt.PackRecno(50), []byte("updated"))
+	require.NoError(t, err)
+	v, err := c.ReadValue(wt.PackRecno(50))
+	require.NoError(t, err)
+	require.EqualValues(t, "updated", string(v))
+
+	err = c.SearchRecno(101)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+}
+
+func TestRecnoFixedLengthTable(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	err := s.Create("table:bitmap", wt.DataSourceCfg{KeyFormat: "r", ValueFormat: "8t"})
+	require.NoError(t, err)
+
+	c, err := s.OpenCursor("table:bitmap")
+	require.NoError(t, err)
+	defer c.Close()
+	err = c.Insert(wt.PackRecno(10), []byte{0xff})
+	require.NoError(t, err)
+	err = c.Insert(wt.PackRecno(20), []byte{0x0f})
+	require.NoError(t, err)
+
+	v, err := c.ReadValue(wt.PackRecno(20))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte{0x0f}, v)
+	// Fixed-length column stores implicitly create all records up to the largest one,
+	// with value 0.
+	err = c.SearchRecno(15)
+	require.NoError(t, err)
+	v, err = c.Value()
+	require.NoError(t, err)
+	require.EqualValues(t, []byte{0}, v)
+	require.NoError(t, c.Reset())
+}
+
+func TestPackRecno(t *testing.T) {
+	for _, recno := range []uint64{1, 63, 64, 8256, 1 << 40} {
+		r, err := wt.UnpackRecno(wt.PackRecno(recno))
+		require.NoError(t, err)
+		require.EqualValues(t, recno, r)
+	}
+}
@@ -0,0 +1,27 @@
+package wt
+
+import (
+	"strings"
+)
+
+// IndexURI returns 'index:' URI for index `name` on table `tableURI`. Index is created
+// with Session.Create call, with DataSourceCfg.Columns listing indexed columns of
+// the table. Index cursors have index columns as keys and table's value columns as
+// values.
+func IndexURI(tableURI, name string) string {
+	return "index:" + strings.TrimPrefix(tableURI, "table:") + ":" + name
+}
+
+// ColgroupURI returns 'colgroup:' URI for column group `name` of table `tableURI`. Table
+// must be created with DataSourceCfg.Colgroups listing all its column groups, and then
+// each column group must be created with Session.Create call, with DataSourceCfg.Columns
+// listing columns that are stored in it.
+func ColgroupURI(tableURI, name string) string {
+	return "colgroup:" + strings.TrimPrefix(tableURI, "table:") + ":" + name
+}
+
+// ProjectionURI returns URI for a cursor that only returns specific value `columns` of a table
+// or an index, i.e. 'table:name(col1,col2)'. Projections are read only.
+func ProjectionURI(uri string, columns ...string) string {
+	return uri + "(" + strings.Join(columns, ",") + ")"
+}
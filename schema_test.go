@@ -0,0 +1,106 @@
+package wt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestSchemaURIs(t *testing.T) {
+	require.EqualValues(t, "index:users:byName", wt.IndexURI("table:users", "byName"))
+	require.EqualValues(t, "colgroup:users:main", wt.ColgroupURI("table:users", "main"))
+	require.EqualValues(t, "table:users(name,email)", wt.ProjectionURI("table:users", "name", "email"))
+}
+
+func TestSchemaIndex(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	users := "table:users"
+	err := s.Create(users, wt.DataSourceCfg{
+		KeyFormat:   "S",
+		ValueFormat: "SSq",
+		Columns:     []string{"id", "name", "email", "age"},
+		Colgroups:   []string{"main", "contacts"},
+	})
+	require.NoError(t, err)
+	err = s.Create(wt.ColgroupURI(users, "main"), wt.DataSourceCfg{Columns: []string{"name", "age"}})
+	require.NoError(t, err)
+	err = s.Create(wt.ColgroupURI(users, "contacts"), wt.DataSourceCfg{Columns: []string{"email"}})
+	require.NoError(t, err)
+	byName := wt.IndexURI(users, "byName")
+	err = s.Create(byName, wt.DataSourceCfg{Columns: []string{"name"}})
+	require.NoError(t, err)
+	byAge := wt.IndexURI(users, "byAge")
+	err = s.Create(byAge, wt.DataSourceCfg{Columns: []string{"age", "name"}})
+	require.NoError(t, err)
+
+	c, err := s.OpenTypedCursor(users)
+	require.NoError(t, err)
+	defer c.Close()
+	err = c.Insert([]interface{}{"u1"}, []interface{}{"alice", "alice@test", 30})
+	require.NoError(t, err)
+	err = c.Insert([]interface{}{"u2"}, []interface{}{"bob", "bob@test", 25})
+	require.NoError(t, err)
+	err = c.Insert([]interface{}{"u3"}, []interface{}{"carol", "carol@test", 35})
+	require.NoError(t, err)
+
+	ci, err := s.OpenTypedCursor(byName)
+	require.NoError(t, err)
+	defer ci.Close()
+	require.EqualValues(t, "S", ci.KeyFormat())
+	require.EqualValues(t, "SSq", ci.ValueFormat())
+	readByName := func(name string) (string, error) {
+		defer ci.Reset()
+		if err := ci.Search(name); err != nil {
+			return "", err
+		}
+		var email string
+		err := ci.Value(nil, &email, nil)
+		return email, err
+	}
+	email, err := readByName("bob")
+	require.NoError(t, err)
+	require.EqualValues(t, "bob@test", email)
+
+	// Index must be kept consistent with updates and removals.
+	err = c.UpdateValue([]interface{}{"u2"}, []interface{}{"robert", "robert@test", 26})
+	require.NoError(t, err)
+	_, err = readByName("bob")
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+	email, err = readByName("robert")
+	require.NoError(t, err)
+	require.EqualValues(t, "robert@test", email)
+
+	err = c.RemoveKey("u1")
+	require.NoError(t, err)
+	_, err = readByName("alice")
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	// Index on multiple columns, with projection that only reads some of the columns.
+	cp, err := s.OpenTypedCursor(wt.ProjectionURI(byAge, "id", "email"))
+	require.NoError(t, err)
+	defer cp.Close()
+	require.EqualValues(t, "qS", cp.KeyFormat())
+	require.EqualValues(t, "SS", cp.ValueFormat())
+	var ids []string
+	for {
+		if err := cp.Next(); err != nil {
+			require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+			break
+		}
+		var id string
+		require.NoError(t, cp.Value(&id, nil))
+		ids = append(ids, id)
+	}
+	require.EqualValues(t, []string{"u2", "u3"}, ids)
+
+	// Projection on a table only reads columns from required column groups.
+	ct, err := s.OpenTypedCursor(wt.ProjectionURI(users, "email"))
+	require.NoError(t, err)
+	defer ct.Close()
+	require.EqualValues(t, "S", ct.ValueFormat())
+	require.NoError(t, ct.Search("u3"))
+	require.NoError(t, ct.Value(&email))
+	require.EqualValues(t, "carol@test", email)
+}
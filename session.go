@@ -2,7 +2,7 @@ package wt
 
 /*
 #include <stdlib.h>
-#include <wiredtiger.h>
+#include "callbacks.h"
 
 // Expose WT methods accessed through function pointers:
 int wt_session_close(
@@ -56,27 +56,71 @@ int wt_session_rollback_transaction(
 	) {
     return session->rollback_transaction(session, NULL);
 }
+int wt_session_transaction_sync(
+	WT_SESSION *session,
+	_GoString_ config
+	) {
+    return session->transaction_sync(session, _GoStringPtr(config));
+}
 */
 import "C"
 
 import (
+	"errors"
+	"strconv"
+	"time"
 	"unsafe"
 )
 
 // Session is a wrapper for WT_SESSION class.
 type Session struct {
-	s    *C.WT_SESSION
-	inTx bool
+	s       *C.WT_SESSION
+	conn    *Connection
+	inTx    bool
+	cursors int
+	// Last error reported by the event handler, same as WT_SESSION::app_private.
+	lastError *C.wt_session_error
+	// Idle cursors that are cached for reuse by typed wrappers, such as Table. Cached
+	// cursors aren't included in the count of open cursors.
+	cursorCache map[cursorCacheKey]*Cursor
+}
+
+type cursorCacheKey struct {
+	uri string
+	cfg CursorCfg
 }
 
 // Close performs WT_SESSION:close call.
 func (s *Session) Close() error {
+	s.cursorCache = nil // Cached cursors are closed by WT_SESSION::close call.
 	r := C.wt_session_close(s.s)
 	s.s = nil
-	if r != 0 {
-		return wtError(r)
+	err := s.sessionError(r, "close", "")
+	s.conn.freeSessionError(unsafe.Pointer(s.lastError))
+	s.lastError = nil
+	return err
+}
+
+// lastErrorMessage returns message of the last error that WiredTiger has reported for
+// the session, if it matches `errorCode`. It must be called for every failed call, since
+// last error is always cleared, so that stale messages aren't attached to unrelated errors
+// later on.
+func (s *Session) lastErrorMessage(errorCode C.int) string {
+	if s == nil || s.lastError == nil || s.lastError.error == 0 {
+		return ""
 	}
-	return nil
+	var msg string
+	if s.lastError.error == errorCode {
+		msg = C.GoString(&s.lastError.message[0])
+	}
+	s.lastError.error = 0
+	return msg
+}
+
+// OpenCursors returns number of cursors that were opened in this session and that
+// haven't been closed yet.
+func (s *Session) OpenCursors() int {
+	return s.cursors
 }
 
 // Closed returns True if session has been explicitly closed using Close() call.
@@ -88,12 +132,19 @@ func (s *Session) Closed() bool {
 type DataSourceCfg struct {
 	AccessPatternHint AccessPatternEnum
 	BlockCompressor   string
+	Colgroups         []string
+	Collator          string
+	Columns           []string
+	Encryption        string
+	Extractor         string
+	KeyFormat         string
 	Type              string
 	LeafKeyMax        int
 	LeafPageMax       int
 	LeafValueMax      int
 	MemoryPageMax     int
 	SplitPct          int
+	ValueFormat       string
 }
 
 // AccessPatternEnum enumerates configuration options for 'access_pattern_hint'.
@@ -112,7 +163,7 @@ func (s *Session) Create(name string, cfg ...DataSourceCfg) error {
 	defer C.free(unsafe.Pointer(nameC))
 	cfgC := configC(cfg)
 	if r := C.wt_session_create(s.s, nameC, cfgC); r != 0 {
-		return wtError(r)
+		return s.sessionError(r, "create", name)
 	}
 	return nil
 }
@@ -123,38 +174,154 @@ type DropCfg struct {
 	RemoveFiles wtBool
 }
 
-// Drop performs WT_SESSION::drop call.
+// Drop performs WT_SESSION::drop call. Cached cursors of the session are closed first,
+// since drop fails with EBUSY while data source has open cursors.
 func (s *Session) Drop(name string, cfg ...DropCfg) error {
+	if err := s.closeCachedCursors(); err != nil {
+		return err
+	}
 	nameC := C.CString(name)
 	defer C.free(unsafe.Pointer(nameC))
 	cfgC := configC(cfg)
 	r := C.wt_session_drop(s.s, nameC, cfgC)
-	return wtError(r)
+	return s.sessionError(r, "drop", name)
 }
 
 // CursorCfg contains options for WT_SESSION::open_cursor call.
 type CursorCfg struct {
-	Bulk      wtBool
-	Overwrite wtBool
-	Readonly  wtBool
-	ReadOnce  wtBool
-	raw       wtBool
+	Append               wtBool
+	Bulk                 wtBool
+	NextRandom           wtBool
+	NextRandomSampleSize int
+	Overwrite            wtBool
+	Readonly             wtBool
+	ReadOnce             wtBool
+	raw                  wtBool
 }
 
 // OpenCursor performs WT_SESSION::open_cursor call.
 func (s *Session) OpenCursor(uri string, cfg ...CursorCfg) (*Cursor, error) {
 	uriC := C.CString(uri)
 	defer C.free(unsafe.Pointer(uriC))
-	var cfgC string
-	if len(cfg) >= 1 {
-		cfg[0].raw = True
-		cfgC = configC(cfg)
-	} else {
-		cfgC = "raw\x00"
-	}
-	c := &Cursor{}
+	cfgC := cursorConfigC(cfg)
+	c := &Cursor{s: s}
 	r := C.wt_session_open_cursor(s.s, uriC, nil, cfgC, &c.c)
-	return c, wtError(r)
+	if r == 0 {
+		s.cursors++
+	}
+	return c, s.sessionError(r, "open_cursor", uri)
+}
+
+// cachedCursor returns cursor opened on `uri` with `cfg` config, reusing idle cursor
+// from the session's cache if there is one. Cursor must be returned to the cache with
+// releaseCachedCursor call once caller is done with it.
+func (s *Session) cachedCursor(uri string, cfg CursorCfg) (*Cursor, error) {
+	key := cursorCacheKey{uri: uri, cfg: cfg}
+	if c, ok := s.cursorCache[key]; ok {
+		delete(s.cursorCache, key)
+		s.cursors++
+		return c, nil
+	}
+	return s.OpenCursor(uri, cfg)
+}
+
+// releaseCachedCursor resets cursor that was returned by cachedCursor call and puts it
+// back into the session's cache. If cache already has cursor for the same `uri` and `cfg`,
+// cursor is closed instead.
+func (s *Session) releaseCachedCursor(c *Cursor, uri string, cfg CursorCfg) error {
+	if err := c.Reset(); err != nil {
+		_ = c.Close()
+		return err
+	}
+	key := cursorCacheKey{uri: uri, cfg: cfg}
+	if _, ok := s.cursorCache[key]; ok {
+		return c.Close()
+	}
+	if s.cursorCache == nil {
+		s.cursorCache = make(map[cursorCacheKey]*Cursor)
+	}
+	s.cursorCache[key] = c
+	s.cursors--
+	return nil
+}
+
+func (s *Session) closeCachedCursors() error {
+	var err error
+	for key, c := range s.cursorCache {
+		delete(s.cursorCache, key)
+		s.cursors++
+		if errClose := c.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+	return err
+}
+
+// cursorConfigC encodes cursor config. All cursors are always opened in 'raw' mode.
+func cursorConfigC(cfg []CursorCfg) string {
+	if len(cfg) == 0 {
+		return "raw\x00"
+	}
+	cfg[0].raw = True
+	return configC(cfg)
+}
+
+// DupCursor duplicates cursor using WT_SESSION::open_cursor call with `to_dup` argument.
+// New cursor is opened on the same data source and it is positioned on the same key as
+// `c`, if `c` is positioned.
+func (s *Session) DupCursor(c *Cursor, cfg ...CursorCfg) (*Cursor, error) {
+	cfgC := cursorConfigC(cfg)
+	dup := &Cursor{s: s}
+	r := C.wt_session_open_cursor(s.s, nil, c.c, cfgC, &dup.c)
+	if r == 0 {
+		s.cursors++
+	}
+	return dup, s.sessionError(r, "open_cursor", "")
+}
+
+// ErrInvalidSampleSize is returned by OpenRandomCursor call for negative sample sizes.
+var ErrInvalidSampleSize = errors.New("sample size must not be negative")
+
+// OpenRandomCursor opens cursor with 'next_random' option. Each Next call on such cursor
+// returns a random record. If `sampleSize` is > 0, cursor divides the table into
+// `sampleSize` pieces and returns records from each piece, which provides better
+// distribution for samples of that size. Random cursor only supports Next, Reset and
+// Close calls. Returns ErrInvalidSampleSize if `sampleSize` is negative.
+func (s *Session) OpenRandomCursor(uri string, sampleSize int) (*Cursor, error) {
+	if sampleSize < 0 {
+		return nil, ErrInvalidSampleSize
+	}
+	return s.OpenCursor(uri, CursorCfg{NextRandom: True, NextRandomSampleSize: sampleSize})
+}
+
+// SampleKeys returns approximately random sample of `n` keys from the table. Returned
+// keys can contain duplicates and aren't sorted. If table is empty or `n` is <= 0,
+// returns no keys. This is useful for estimating key distribution without doing a full
+// scan.
+func (s *Session) SampleKeys(uri string, n int) ([][]byte, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	c, err := s.OpenRandomCursor(uri, n)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	keys := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		if err := c.Next(); err != nil {
+			if ErrCode(err) == ErrNotFound {
+				break
+			}
+			return nil, err
+		}
+		k, err := c.Key()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
 }
 
 // SyncMode describes different synchronization options.
@@ -164,14 +331,16 @@ type SyncMode string
 const (
 	SyncOff = "off"
 	SyncOn  = "on"
-	// SyncBackground = "background" (APIs for Background mode not implemented)
+	// SyncBackground starts syncing the log in the background and returns right away.
+	// Use TransactionSync call to wait until the sync completes.
+	SyncBackground = "background"
 )
 
 // LogFlush performs WT_SESSION::log_flush call.
 func (s *Session) LogFlush(sync SyncMode) error {
 	cfgC := "sync=" + string(sync) + "\x00"
 	if r := C.wt_session_log_flush(s.s, cfgC); r != 0 {
-		return wtError(r)
+		return s.sessionError(r, "log_flush", "")
 	}
 	return nil
 }
@@ -187,7 +356,7 @@ func (s *Session) TxBegin(cfg ...TxCfg) error {
 	cfgC := configC(cfg)
 	r := C.wt_session_begin_transaction(s.s, cfgC)
 	s.inTx = (r == 0)
-	return wtError(r)
+	return s.sessionError(r, "begin_transaction", "")
 }
 
 // TxCommit performs WT_SESSION::commit_transaction call.
@@ -195,14 +364,29 @@ func (s *Session) TxCommit(cfg ...TxCfg) error {
 	cfgC := configC(cfg)
 	r := C.wt_session_commit_transaction(s.s, cfgC)
 	s.inTx = false
-	return wtError(r)
+	return s.sessionError(r, "commit_transaction", "")
 }
 
 // TxRollback performs WT_SESSION::rollback_transaction call.
 func (s *Session) TxRollback() error {
 	r := C.wt_session_rollback_transaction(s.s)
 	s.inTx = false
-	return wtError(r)
+	return s.sessionError(r, "rollback_transaction", "")
+}
+
+// TransactionSync performs WT_SESSION::transaction_sync call. It waits until log records
+// of the last transaction committed by this session are durable, which requires a prior
+// LogFlush(SyncBackground) call after that commit. Returns ErrTimedOut if records aren't
+// durable after `timeout`. If `timeout` is <= 0, it only checks and doesn't wait at all.
+// Must be called outside of a transaction.
+func (s *Session) TransactionSync(timeout time.Duration) error {
+	timeoutMs := int64(0)
+	if timeout > 0 {
+		timeoutMs = int64((timeout + time.Millisecond - 1) / time.Millisecond)
+	}
+	cfgC := "timeout_ms=" + strconv.FormatInt(timeoutMs, 10) + "\x00"
+	r := C.wt_session_transaction_sync(s.s, cfgC)
+	return s.sessionError(r, "transaction_sync", "")
 }
 
 // InTx returns True, if transaction was started using TxBegin call and has not yet
@@ -1,28 +1,19 @@
-package wt
+package wt_test
 
 import (
-	"io/ioutil"
-	"os"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
 )
 
 func TestSession(t *testing.T) {
-	dbDir, err := ioutil.TempDir("", "wt_")
-	require.NoError(t, err)
-	defer os.RemoveAll(dbDir)
-
-	c, err := Open(dbDir, ConnCfg{Create: True, Log: "enabled"})
-	require.NoError(t, err)
-	defer func() { require.NoError(t, c.Close()) }()
-
-	s, err := c.OpenSession()
-	require.NoError(t, err)
-	defer func() { require.NoError(t, s.Close()) }()
-
-	err = s.Create("table:test_table", DataSourceCfg{BlockCompressor: "snappy"})
-	require.NoError(t, err)
+	s := wttest.NewDB(t, wttest.DBOpts{
+		Cfg:      wt.ConnCfg{Log: "enabled"},
+		TableCfg: wt.DataSourceCfg{BlockCompressor: "snappy"},
+	}).Session
 
 	cc, err := s.OpenCursor("table:test_table")
 	require.NoError(t, err)
@@ -44,11 +35,11 @@ func TestSession(t *testing.T) {
 
 	err = cc.Search([]byte("testkey3"))
 	require.Error(t, err)
-	require.EqualValues(t, ErrNotFound, ErrCode(err))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
 
 	near, err := cc.SearchNear([]byte("testkey3"))
 	require.NoError(t, err)
-	require.EqualValues(t, MatchedSmaller, near)
+	require.EqualValues(t, wt.MatchedSmaller, near)
 	v, err = cc.UnsafeValue()
 	require.NoError(t, err)
 	require.EqualValues(t, []byte("testvalue2"), v)
@@ -64,7 +55,7 @@ func TestSession(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, []byte(nil), v)
 
-	err = s.LogFlush(SyncOn) // Flush log for good measure.
+	err = s.LogFlush(wt.SyncOn) // Flush log for good measure.
 	require.NoError(t, err)
 
 	err = s.Drop("table:test_table")
@@ -76,21 +67,10 @@ func TestSession(t *testing.T) {
 }
 
 func TestSessionTxs(t *testing.T) {
-	dbDir, err := ioutil.TempDir("", "wt_")
-	require.NoError(t, err)
-	defer os.RemoveAll(dbDir)
-
-	c, err := Open(dbDir, ConnCfg{Create: True})
-	require.NoError(t, err)
-	defer func() { require.NoError(t, c.Close()) }()
+	db := wttest.NewDB(t)
+	s1 := db.Session
 
-	s1, err := c.OpenSession()
-	require.NoError(t, err)
-	defer func() { require.NoError(t, s1.Close()) }()
-	err = s1.Create("table:test_table")
-	require.NoError(t, err)
-
-	s2, err := c.OpenSession()
+	s2, err := db.Conn.OpenSession()
 	require.NoError(t, err)
 	defer func() { require.NoError(t, s2.Close()) }()
 
@@ -107,7 +87,7 @@ func TestSessionTxs(t *testing.T) {
 	require.NoError(t, err)
 	_, err = c2.ReadUnsafeValue([]byte("testkey1"))
 	require.Error(t, err)
-	require.EqualValues(t, ErrNotFound, ErrCode(err))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
 
 	err = s1.TxCommit()
 	require.NoError(t, err)
@@ -117,3 +97,99 @@ func TestSessionTxs(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, []byte("testvalue1"), v)
 }
+
+func TestSessionTxReserve(t *testing.T) {
+	db := wttest.NewDB(t)
+	s1 := db.Session
+	s2, err := db.Conn.OpenSession()
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s2.Close()) }()
+
+	c1, err := s1.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c1.Close()
+	c2, err := s2.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c2.Close()
+	err = c1.Insert([]byte("testkey1"), []byte("testvalue1"))
+	require.NoError(t, err)
+
+	err = c1.Reserve([]byte("testkey1"))
+	require.EqualValues(t, wt.ErrTxRequired, err)
+
+	err = s1.TxBegin()
+	require.NoError(t, err)
+	err = c1.Reserve([]byte("testkey1"))
+	require.NoError(t, err)
+	err = c1.Reserve([]byte("testkey2"))
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	// Conflicting write from other transaction must fail while record is reserved.
+	err = s2.TxBegin()
+	require.NoError(t, err)
+	err = c2.UpdateValue([]byte("testkey1"), []byte("testvalue2"))
+	require.EqualValues(t, wt.ErrRollback, wt.ErrCode(err))
+	require.NoError(t, s2.TxRollback())
+
+	err = s1.TxCommit()
+	require.NoError(t, err)
+
+	// Reserve doesn't change the value, and once transaction finishes,
+	// record can be updated again.
+	v, err := c2.ReadValue([]byte("testkey1"))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte("testvalue1"), v)
+	err = c2.UpdateValue([]byte("testkey1"), []byte("testvalue2"))
+	require.NoError(t, err)
+}
+
+func TestSessionSampleKeys(t *testing.T) {
+	s := wttest.NewDB(t).Session
+
+	keys, err := s.SampleKeys("table:test_table", 10)
+	require.NoError(t, err)
+	require.Len(t, keys, 0)
+
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+	for i := 0; i < 1000; i++ {
+		err := c.Insert([]byte(fmt.Sprintf("testkey%04d", i)), []byte("testvalue"))
+		require.NoError(t, err)
+	}
+
+	keys, err = s.SampleKeys("table:test_table", 10)
+	require.NoError(t, err)
+	require.Len(t, keys, 10)
+	distinct := make(map[string]bool)
+	for _, k := range keys {
+		_, err := c.ReadValue(k)
+		require.NoError(t, err)
+		distinct[string(k)] = true
+	}
+	require.Greater(t, len(distinct), 1)
+	// Sample must not be just the first records of the table.
+	require.False(t, distinct["testkey0000"] && distinct["testkey0001"] && distinct["testkey0002"])
+
+	keys, err = s.SampleKeys("table:test_table", -1)
+	require.NoError(t, err)
+	require.Len(t, keys, 0)
+	_, err = s.OpenRandomCursor("table:test_table", -1)
+	require.Equal(t, wt.ErrInvalidSampleSize, err)
+}
+
+func TestSessionOpenCursors(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	require.EqualValues(t, 0, s.OpenCursors())
+	c1, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	c2, err := s.DupCursor(c1)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, s.OpenCursors())
+	require.NoError(t, c1.Close())
+	require.NoError(t, c2.Close())
+	require.EqualValues(t, 0, s.OpenCursors())
+	_, err = s.OpenCursor("table:missing")
+	require.Error(t, err)
+	require.EqualValues(t, 0, s.OpenCursors())
+}
@@ -0,0 +1,148 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include <wiredtiger.h>
+
+// Reads single value from a statistics cursor.
+int wt_session_read_stat(
+	WT_SESSION *session,
+	const char *uri,
+	_GoString_ config,
+	int key,
+	int64_t *valuep) {
+	WT_CURSOR *cursor;
+	int r = session->open_cursor(session, uri, NULL, _GoStringPtr(config), &cursor);
+	if (r != 0) {
+		return r;
+	}
+	cursor->set_key(cursor, key);
+	r = cursor->search(cursor);
+	if (r == 0) {
+		const char *desc, *pvalue;
+		int64_t value;
+		r = cursor->get_value(cursor, &desc, &pvalue, &value);
+		if (r == 0) {
+			*valuep = value;
+		}
+	}
+	int r_close = cursor->close(cursor);
+	return r != 0 ? r : r_close;
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"unsafe"
+)
+
+// SizeEstimate describes approximate size of a data source.
+type SizeEstimate struct {
+	// Bytes is the size of the data source on disk.
+	Bytes int64
+	// Records is the number of key/value pairs. It is -1, if it couldn't be
+	// computed, i.e. because statistics are disabled for the connection.
+	Records int64
+}
+
+// EstimateSize returns approximate size of the data source using data source statistics.
+// Bytes are read using 'statistics=(size)' config, that is always cheap and available.
+// Records are read using 'statistics=(fast,tree_walk)' config, which requires statistics
+// to be enabled for the connection, and which walks the in-memory tree, thus it can be
+// relatively expensive for large tables. If Records can't be read, i.e. because statistics
+// are disabled, Records is set to -1. Errors are only returned if Bytes can't be read.
+func (s *Session) EstimateSize(uri string) (SizeEstimate, error) {
+	statsURI := C.CString("statistics:" + uri)
+	defer C.free(unsafe.Pointer(statsURI))
+	var r SizeEstimate
+	var v C.int64_t
+	if rr := C.wt_session_read_stat(
+		s.s, statsURI, "statistics=(size)\x00", C.WT_STAT_DSRC_BLOCK_SIZE, &v); rr != 0 {
+		return SizeEstimate{}, s.sessionError(rr, "open_cursor", "statistics:"+uri)
+	}
+	r.Bytes = int64(v)
+	if rr := C.wt_session_read_stat(
+		s.s, statsURI, "statistics=(fast,tree_walk)\x00", C.WT_STAT_DSRC_BTREE_ENTRIES, &v); rr == 0 {
+		r.Records = int64(v)
+	} else {
+		s.lastErrorMessage(rr) // Error isn't returned, thus its message must be cleared.
+		r.Records = -1
+	}
+	return r, nil
+}
+
+// estimateSampleSize is number of keys that are sampled to estimate size of a range.
+const estimateSampleSize = 1000
+
+// EstimateRangeSize returns approximate size of keys in [start, end) range. Empty `start` or
+// `end` means no bound. Estimate is computed by scaling results of EstimateSize call with
+// the fraction of randomly sampled keys that fall in the range, thus it is fairly
+// inaccurate for small ranges. Returns same errors as EstimateSize call.
+func (s *Session) EstimateRangeSize(uri string, start, end []byte) (SizeEstimate, error) {
+	total, err := s.EstimateSize(uri)
+	if err != nil {
+		return SizeEstimate{}, err
+	}
+	keys, err := s.SampleKeys(uri, estimateSampleSize)
+	if err != nil {
+		return SizeEstimate{}, err
+	}
+	if len(keys) == 0 {
+		return SizeEstimate{Bytes: 0, Records: 0}, nil
+	}
+	inRange := 0
+	for _, k := range keys {
+		if (len(start) == 0 || bytes.Compare(k, start) >= 0) &&
+			(len(end) == 0 || bytes.Compare(k, end) < 0) {
+			inRange++
+		}
+	}
+	r := SizeEstimate{Bytes: total.Bytes * int64(inRange) / int64(len(keys)), Records: -1}
+	if total.Records >= 0 {
+		r.Records = total.Records * int64(inRange) / int64(len(keys))
+	}
+	return r, nil
+}
+
+// CacheStats describes usage of the connection cache.
+type CacheStats struct {
+	// BytesInUse is the number of bytes currently in the cache.
+	BytesInUse int64
+	// BytesMax is the configured size of the cache.
+	BytesMax int64
+	// BytesDirty is the number of bytes of modified data in the cache.
+	BytesDirty int64
+}
+
+// Usage returns fraction of the cache that is in use. For in-memory databases, operations
+// start failing with ErrCacheAll error once usage gets close to 1.
+func (c CacheStats) Usage() float64 {
+	if c.BytesMax <= 0 {
+		return 0
+	}
+	return float64(c.BytesInUse) / float64(c.BytesMax)
+}
+
+// CacheStats reads cache statistics of the connection. Requires statistics to be enabled
+// for the connection.
+func (s *Session) CacheStats() (CacheStats, error) {
+	statsURI := C.CString("statistics:")
+	defer C.free(unsafe.Pointer(statsURI))
+	var r CacheStats
+	for _, stat := range []struct {
+		key   C.int
+		value *int64
+	}{
+		{C.WT_STAT_CONN_CACHE_BYTES_INUSE, &r.BytesInUse},
+		{C.WT_STAT_CONN_CACHE_BYTES_MAX, &r.BytesMax},
+		{C.WT_STAT_CONN_CACHE_BYTES_DIRTY, &r.BytesDirty},
+	} {
+		var v C.int64_t
+		if rr := C.wt_session_read_stat(s.s, statsURI, "statistics=(fast)\x00", stat.key, &v); rr != 0 {
+			return CacheStats{}, s.sessionError(rr, "open_cursor", "statistics:")
+		}
+		*stat.value = int64(v)
+	}
+	return r, nil
+}
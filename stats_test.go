@@ -0,0 +1,93 @@
+package wt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestSessionEstimateSize(t *testing.T) {
+	s := wttest.NewDB(t, wttest.DBOpts{
+		Cfg: wt.ConnCfg{Statistics: []wt.StatisticsEnum{wt.StatsFast}},
+	}).Session
+
+	cc, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer cc.Close()
+	for i := 0; i < 1000; i++ {
+		err := cc.Insert([]byte(fmt.Sprintf("testkey%04d", i)), []byte("testvalue"))
+		require.NoError(t, err)
+	}
+
+	size, err := s.EstimateSize("table:test_table")
+	require.NoError(t, err)
+	require.Greater(t, size.Bytes, int64(0))
+	require.EqualValues(t, 1000, size.Records)
+
+	size, err = s.EstimateRangeSize("table:test_table", []byte("testkey0500"), nil)
+	require.NoError(t, err)
+	require.InDelta(t, 500, size.Records, 150)
+	size, err = s.EstimateRangeSize("table:test_table", []byte("x"), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, size.Records)
+
+	_, err = s.EstimateSize("table:missing_table")
+	require.Error(t, err)
+}
+
+func TestSessionEstimateSizeNoStats(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	size, err := s.EstimateSize("table:test_table")
+	require.NoError(t, err)
+	require.Greater(t, size.Bytes, int64(0))
+	require.EqualValues(t, -1, size.Records)
+
+	size, err = s.EstimateRangeSize("table:test_table", nil, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, size.Bytes)
+	require.EqualValues(t, 0, size.Records)
+}
+
+func TestCursorLargestKey(t *testing.T) {
+	db := wttest.NewDB(t)
+	s := db.Session
+	c, err := s.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.LargestKey()
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	for i := 0; i < 10; i++ {
+		err := c.Insert([]byte(fmt.Sprintf("testkey%02d", i)), []byte("testvalue"))
+		require.NoError(t, err)
+	}
+	k, err := c.LargestKey()
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey09", string(k))
+
+	// Keys of in-progress transactions must be returned too.
+	s2, err := db.Conn.OpenSession()
+	require.NoError(t, err)
+	defer s2.Close()
+	c2, err := s2.OpenCursor("table:test_table")
+	require.NoError(t, err)
+	defer c2.Close()
+	require.NoError(t, s2.TxBegin())
+	require.NoError(t, c2.Insert([]byte("testkey10"), []byte("testvalue")))
+	k, err = c.LargestKey()
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey10", string(k))
+	require.NoError(t, s2.TxRollback())
+
+	// Cursor must be reset and usable inside a transaction.
+	require.NoError(t, s.TxBegin())
+	k, err = c.LargestKey()
+	require.NoError(t, err)
+	require.EqualValues(t, "testkey09", string(k))
+	require.NoError(t, c.Next())
+	require.NoError(t, s.TxCommit())
+}
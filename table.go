@@ -0,0 +1,240 @@
+package wt
+
+import (
+	"errors"
+	"sync"
+)
+
+// Table is a typed wrapper for a data source, that encodes keys and values using
+// Codecs. Table itself doesn't hold any WiredTiger resources, thus it is safe to use
+// it concurrently from multiple goroutines, as long as each goroutine uses its own
+// Session. All operations use provided Session, thus they participate in its
+// transaction, if one was started with TxBegin call.
+//
+// Operations reuse cursors that are cached in the Session, instead of opening a new
+// cursor for each call. Cached cursors are reset after each operation.
+type Table[K, V any] struct {
+	uri        string
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+	bufs       sync.Pool
+}
+
+type tableBufs struct {
+	key   []byte
+	value []byte
+	end   []byte
+}
+
+// NewTable creates typed wrapper for data source at `uri`. It doesn't create data
+// source itself, see Table.Create for that.
+func NewTable[K, V any](uri string, keyCodec Codec[K], valueCodec Codec[V]) *Table[K, V] {
+	return &Table[K, V]{
+		uri:        uri,
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+		bufs:       sync.Pool{New: func() interface{} { return &tableBufs{} }},
+	}
+}
+
+// URI returns uri of the data source.
+func (t *Table[K, V]) URI() string {
+	return t.uri
+}
+
+// Create performs WT_SESSION::create call for the data source.
+func (t *Table[K, V]) Create(s *Session, cfg ...DataSourceCfg) error {
+	return s.Create(t.uri, cfg...)
+}
+
+// ErrEmptyKey is returned by Table operations when key is encoded as an empty byte slice,
+// since WiredTiger doesn't support empty keys.
+var ErrEmptyKey = errors.New("empty keys are not supported")
+
+// tableCursorCfg is config of cursors used for reads and writes. Deletes use
+// 'overwrite=false' cursors, so that removing missing key returns ErrNotFound.
+var (
+	tableCursorCfg       = CursorCfg{}
+	tableDeleteCursorCfg = CursorCfg{Overwrite: False}
+)
+
+// Get returns value for `key`. Returns ErrNotFound if key doesn't exist.
+func (t *Table[K, V]) Get(s *Session, key K) (V, error) {
+	var value V
+	bufs := t.bufs.Get().(*tableBufs)
+	defer t.bufs.Put(bufs)
+	if err := t.encodeKey(bufs, key); err != nil {
+		return value, err
+	}
+	c, err := s.cachedCursor(t.uri, tableCursorCfg)
+	if err != nil {
+		return value, err
+	}
+	v, err := c.ReadUnsafeValue(bufs.key)
+	if err == nil {
+		value, err = t.valueCodec.Decode(v)
+	}
+	if errRelease := s.releaseCachedCursor(c, t.uri, tableCursorCfg); err == nil {
+		err = errRelease
+	}
+	return value, err
+}
+
+// Put inserts or overwrites value for `key`.
+func (t *Table[K, V]) Put(s *Session, key K, value V) error {
+	bufs := t.bufs.Get().(*tableBufs)
+	defer t.bufs.Put(bufs)
+	if err := t.encodeKey(bufs, key); err != nil {
+		return err
+	}
+	var err error
+	bufs.value, err = t.valueCodec.Append(bufs.value[:0], value)
+	if err != nil {
+		return err
+	}
+	c, err := s.cachedCursor(t.uri, tableCursorCfg)
+	if err != nil {
+		return err
+	}
+	err = c.Insert(bufs.key, bufs.value)
+	if errRelease := s.releaseCachedCursor(c, t.uri, tableCursorCfg); err == nil {
+		err = errRelease
+	}
+	return err
+}
+
+// Delete removes `key`. Returns ErrNotFound if key doesn't exist.
+func (t *Table[K, V]) Delete(s *Session, key K) error {
+	bufs := t.bufs.Get().(*tableBufs)
+	defer t.bufs.Put(bufs)
+	if err := t.encodeKey(bufs, key); err != nil {
+		return err
+	}
+	c, err := s.cachedCursor(t.uri, tableDeleteCursorCfg)
+	if err != nil {
+		return err
+	}
+	err = c.RemoveKey(bufs.key)
+	if errRelease := s.releaseCachedCursor(c, t.uri, tableDeleteCursorCfg); err == nil {
+		err = errRelease
+	}
+	return err
+}
+
+// encodeKey encodes `key` into bufs.key. Returns ErrEmptyKey if key is encoded as an
+// empty byte slice.
+func (t *Table[K, V]) encodeKey(bufs *tableBufs, key K) (err error) {
+	bufs.key, err = t.keyCodec.Append(bufs.key[:0], key)
+	if err == nil && len(bufs.key) == 0 {
+		err = ErrEmptyKey
+	}
+	return err
+}
+
+// Scan returns iterator over all key/value pairs in the table.
+func (t *Table[K, V]) Scan(s *Session) *TableIter[K, V] {
+	return t.newIter(s, nil, nil)
+}
+
+// Range returns iterator over key/value pairs with keys in [start, end) range.
+func (t *Table[K, V]) Range(s *Session, start, end K) *TableIter[K, V] {
+	return t.newIter(s, &start, &end)
+}
+
+// RangeFrom returns iterator over key/value pairs with keys that are >= `start`.
+func (t *Table[K, V]) RangeFrom(s *Session, start K) *TableIter[K, V] {
+	return t.newIter(s, &start, nil)
+}
+
+// RangeTo returns iterator over key/value pairs with keys that are < `end`.
+func (t *Table[K, V]) RangeTo(s *Session, end K) *TableIter[K, V] {
+	return t.newIter(s, nil, &end)
+}
+
+func (t *Table[K, V]) newIter(s *Session, start, end *K) *TableIter[K, V] {
+	it := &TableIter[K, V]{t: t, s: s, bufs: t.bufs.Get().(*tableBufs)}
+	opts := RangeOpts{}
+	if start != nil {
+		it.bufs.key, it.err = t.keyCodec.Append(it.bufs.key[:0], *start)
+		if it.err != nil {
+			return it
+		}
+		opts.Start = it.bufs.key
+	}
+	if end != nil {
+		it.bufs.end, it.err = t.keyCodec.Append(it.bufs.end[:0], *end)
+		if it.err != nil {
+			return it
+		}
+		if len(it.bufs.end) == 0 {
+			return it // No keys are smaller than an empty key.
+		}
+		opts.End = it.bufs.end
+	}
+	it.c, it.err = s.cachedCursor(t.uri, tableCursorCfg)
+	if it.err != nil {
+		return it
+	}
+	it.it = it.c.Range(opts)
+	return it
+}
+
+// TableIter iterates over typed key/value pairs of a Table. Must be closed with Close
+// call once it is no longer needed.
+type TableIter[K, V any] struct {
+	t     *Table[K, V]
+	s     *Session
+	bufs  *tableBufs
+	c     *Cursor
+	it    *RangeIter
+	key   K
+	value V
+	err   error
+}
+
+// Next moves iterator to the next key/value pair. Returns false when there are no more
+// pairs left, or if an error occurs. Use Err call to distinguish between the two.
+func (it *TableIter[K, V]) Next() bool {
+	if it.err != nil || it.it == nil {
+		return false
+	}
+	if !it.it.Next() {
+		it.err = it.it.Err()
+		return false
+	}
+	it.key, it.err = it.t.keyCodec.Decode(it.it.Key())
+	if it.err != nil {
+		return false
+	}
+	it.value, it.err = it.t.valueCodec.Decode(it.it.Value())
+	return it.err == nil
+}
+
+// Key returns key of the current pair.
+func (it *TableIter[K, V]) Key() K {
+	return it.key
+}
+
+// Value returns value of the current pair.
+func (it *TableIter[K, V]) Value() V {
+	return it.value
+}
+
+// Err returns error that stopped the iteration, if any.
+func (it *TableIter[K, V]) Err() error {
+	return it.err
+}
+
+// Close returns underlying cursor to the session's cursor cache.
+func (it *TableIter[K, V]) Close() error {
+	var err error
+	if it.c != nil {
+		err = it.s.releaseCachedCursor(it.c, it.t.uri, tableCursorCfg)
+		it.c, it.it = nil, nil
+	}
+	if it.bufs != nil {
+		it.t.bufs.Put(it.bufs)
+		it.bufs = nil
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package wt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+type testDoc struct {
+	Name  string
+	Count int
+}
+
+func TestTable(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	table := wt.NewTable[int64, testDoc]("table:docs", wt.Int64Codec{}, wt.JSONCodec[testDoc]{})
+	require.NoError(t, table.Create(s))
+
+	for _, id := range []int64{-2, -1, 0, 1, 2} {
+		err := table.Put(s, id, testDoc{Name: "doc", Count: int(id)})
+		require.NoError(t, err)
+	}
+	doc, err := table.Get(s, -1)
+	require.NoError(t, err)
+	require.EqualValues(t, testDoc{Name: "doc", Count: -1}, doc)
+	_, err = table.Get(s, 3)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	it := table.Scan(s)
+	var ids []int64
+	for it.Next() {
+		require.EqualValues(t, it.Key(), it.Value().Count)
+		ids = append(ids, it.Key())
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.EqualValues(t, []int64{-2, -1, 0, 1, 2}, ids)
+
+	it = table.Range(s, -1, 1)
+	ids = nil
+	for it.Next() {
+		ids = append(ids, it.Key())
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.EqualValues(t, []int64{-1, 0}, ids)
+
+	it = table.RangeFrom(s, 1)
+	ids = nil
+	for it.Next() {
+		// Operations must work while iterator is open.
+		_, err := table.Get(s, it.Key())
+		require.NoError(t, err)
+		ids = append(ids, it.Key())
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.EqualValues(t, []int64{1, 2}, ids)
+
+	it = table.RangeTo(s, -1)
+	ids = nil
+	for it.Next() {
+		ids = append(ids, it.Key())
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.EqualValues(t, []int64{-2}, ids)
+	// Cached cursors must not be reported as open.
+	require.EqualValues(t, 0, s.OpenCursors())
+
+	// Operations must participate in session's transaction.
+	require.NoError(t, s.TxBegin())
+	require.NoError(t, table.Put(s, 5, testDoc{Name: "tx"}))
+	require.NoError(t, table.Delete(s, 0))
+	doc, err = table.Get(s, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, "tx", doc.Name)
+	require.NoError(t, s.TxRollback())
+
+	_, err = table.Get(s, 5)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+	_, err = table.Get(s, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, table.Delete(s, 0))
+	err = table.Delete(s, 0)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+
+	// Cached cursors must not prevent data source from being dropped.
+	require.NoError(t, s.Drop(table.URI()))
+}
+
+func TestTableEmptyKey(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	table := wt.NewTable[string, string]("table:strings", wt.StringCodec{}, wt.StringCodec{})
+	require.NoError(t, table.Create(s))
+
+	require.Equal(t, wt.ErrEmptyKey, table.Put(s, "", "value"))
+	_, err := table.Get(s, "")
+	require.Equal(t, wt.ErrEmptyKey, err)
+	require.Equal(t, wt.ErrEmptyKey, table.Delete(s, ""))
+
+	require.NoError(t, table.Put(s, "a", "value"))
+	it := table.Range(s, "", "")
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	it = table.RangeFrom(s, "")
+	require.True(t, it.Next())
+	require.EqualValues(t, "a", it.Key())
+	require.NoError(t, it.Close())
+}
+
+func TestCodecs(t *testing.T) {
+	testCodec(t, wt.StringCodec{}, "test")
+	testCodec(t, wt.BytesCodec{}, []byte("test"))
+	testCodec(t, wt.Uint64Codec{}, uint64(12345))
+	testCodec(t, wt.Int64Codec{}, int64(-12345))
+	testCodec(t, wt.JSONCodec[testDoc]{}, testDoc{Name: "test", Count: 5})
+	testCodec(t, wt.GobCodec[testDoc]{}, testDoc{Name: "test", Count: 5})
+	testCodec(t, wt.BinaryCodec[time.Time, *time.Time]{}, time.Unix(12345, 0).UTC())
+}
+
+func testCodec[T any](t *testing.T, codec wt.Codec[T], v T) {
+	data, err := codec.Append([]byte("prefix"), v)
+	require.NoError(t, err)
+	require.EqualValues(t, "prefix", string(data[:6]))
+	decoded, err := codec.Decode(data[6:])
+	require.NoError(t, err)
+	require.EqualValues(t, v, decoded)
+}
@@ -0,0 +1,137 @@
+package wt
+
+/*
+#include <stdlib.h>
+#include <wiredtiger.h>
+*/
+import "C"
+
+// TypedCursor wraps Cursor and packs keys and values using key_format and value_format
+// of the data source. See Pack for supported formats and argument types.
+type TypedCursor struct {
+	c           *Cursor
+	keyFormat   string
+	valueFormat string
+	kbuf        []byte
+	vbuf        []byte
+}
+
+// OpenTypedCursor performs WT_SESSION::open_cursor call and wraps returned cursor
+// in a TypedCursor.
+func (s *Session) OpenTypedCursor(uri string, cfg ...CursorCfg) (*TypedCursor, error) {
+	c, err := s.OpenCursor(uri, cfg...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedCursor{
+		c:           c,
+		keyFormat:   C.GoString(c.c.key_format),
+		valueFormat: C.GoString(c.c.value_format),
+	}, nil
+}
+
+// Cursor returns underlying raw cursor.
+func (c *TypedCursor) Cursor() *Cursor {
+	return c.c
+}
+
+// KeyFormat returns key_format of the data source.
+func (c *TypedCursor) KeyFormat() string {
+	return c.keyFormat
+}
+
+// ValueFormat returns value_format of the data source.
+func (c *TypedCursor) ValueFormat() string {
+	return c.valueFormat
+}
+
+// Close performs WT_CURSOR::close call.
+func (c *TypedCursor) Close() error {
+	return c.c.Close()
+}
+
+// Reset performs WT_CURSOR::reset call.
+func (c *TypedCursor) Reset() error {
+	return c.c.Reset()
+}
+
+// Next performs WT_CURSOR::next call.
+func (c *TypedCursor) Next() error {
+	return c.c.Next()
+}
+
+// Prev performs WT_CURSOR::prev call.
+func (c *TypedCursor) Prev() error {
+	return c.c.Prev()
+}
+
+// Key unpacks key that cursor is pointing to into `ptrs`.
+func (c *TypedCursor) Key(ptrs ...interface{}) error {
+	k, err := c.c.UnsafeKey()
+	if err != nil {
+		return err
+	}
+	return Unpack(c.keyFormat, k, ptrs...)
+}
+
+// Value unpacks value that cursor is pointing to into `ptrs`.
+func (c *TypedCursor) Value(ptrs ...interface{}) error {
+	v, err := c.c.UnsafeValue()
+	if err != nil {
+		return err
+	}
+	return Unpack(c.valueFormat, v, ptrs...)
+}
+
+// Search performs WT_CURSOR::search call.
+func (c *TypedCursor) Search(key ...interface{}) error {
+	if err := c.packKey(key); err != nil {
+		return err
+	}
+	return c.c.Search(c.kbuf)
+}
+
+// SearchNear performs WT_CURSOR::search_near call.
+func (c *TypedCursor) SearchNear(key ...interface{}) (NearMatchType, error) {
+	if err := c.packKey(key); err != nil {
+		return 0, err
+	}
+	return c.c.SearchNear(c.kbuf)
+}
+
+// Insert performs WT_CURSOR::insert call. Cursor is reset after this call.
+func (c *TypedCursor) Insert(key, value []interface{}) error {
+	if err := c.packKeyValue(key, value); err != nil {
+		return err
+	}
+	return c.c.Insert(c.kbuf, c.vbuf)
+}
+
+// UpdateValue performs WT_CURSOR::update call. Cursor is reset after this call.
+func (c *TypedCursor) UpdateValue(key, value []interface{}) error {
+	if err := c.packKeyValue(key, value); err != nil {
+		return err
+	}
+	return c.c.UpdateValue(c.kbuf, c.vbuf)
+}
+
+// RemoveKey performs WT_CURSOR::remove call. Cursor is reset after this call.
+func (c *TypedCursor) RemoveKey(key ...interface{}) error {
+	if err := c.packKey(key); err != nil {
+		return err
+	}
+	return c.c.RemoveKey(c.kbuf)
+}
+
+func (c *TypedCursor) packKey(key []interface{}) (err error) {
+	c.kbuf, err = AppendPack(c.kbuf[:0], c.keyFormat, key...)
+	return err
+}
+
+func (c *TypedCursor) packKeyValue(key, value []interface{}) (err error) {
+	if err := c.packKey(key); err != nil {
+		return err
+	}
+	c.vbuf, err = AppendPack(c.vbuf[:0], c.valueFormat, value...)
+	return err
+}
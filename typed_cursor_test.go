@@ -0,0 +1,63 @@
+package wt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+	"github.com/zviadm/wt/wttest"
+)
+
+func TestTypedCursor(t *testing.T) {
+	s := wttest.NewDB(t).Session
+	err := s.Create("table:typed_table", wt.DataSourceCfg{KeyFormat: "Sq", ValueFormat: "SQu"})
+	require.NoError(t, err)
+
+	c, err := s.OpenTypedCursor("table:typed_table")
+	require.NoError(t, err)
+	defer c.Close()
+	require.EqualValues(t, "Sq", c.KeyFormat())
+	require.EqualValues(t, "SQu", c.ValueFormat())
+
+	for _, ts := range []int64{-5, 10, 0} {
+		err = c.Insert(
+			[]interface{}{"tenant1", ts},
+			[]interface{}{"name", uint64(ts + 5), []byte("data")})
+		require.NoError(t, err)
+	}
+	err = c.Insert([]interface{}{"tenant1"}, []interface{}{"name", 1, []byte{}})
+	require.Error(t, err)
+
+	// Keys must be sorted by packed integer ordering.
+	var tenant string
+	var ts int64
+	for _, expectedTs := range []int64{-5, 0, 10} {
+		require.NoError(t, c.Next())
+		require.NoError(t, c.Key(&tenant, &ts))
+		require.EqualValues(t, "tenant1", tenant)
+		require.EqualValues(t, expectedTs, ts)
+	}
+
+	err = c.Search("tenant1", int64(0))
+	require.NoError(t, err)
+	var name string
+	var counter uint64
+	var data []byte
+	require.NoError(t, c.Value(&name, &counter, &data))
+	require.EqualValues(t, "name", name)
+	require.EqualValues(t, 5, counter)
+	require.EqualValues(t, "data", string(data))
+
+	err = c.UpdateValue([]interface{}{"tenant1", 0}, []interface{}{"name2", 6, []byte{}})
+	require.NoError(t, err)
+	near, err := c.SearchNear("tenant1", 1)
+	require.NoError(t, err)
+	require.NotEqual(t, wt.MatchedExact, near)
+	require.NoError(t, c.Search("tenant1", 0))
+	require.NoError(t, c.Value(&name, nil, nil))
+	require.EqualValues(t, "name2", name)
+
+	require.NoError(t, c.RemoveKey("tenant1", 0))
+	err = c.Search("tenant1", 0)
+	require.EqualValues(t, wt.ErrNotFound, wt.ErrCode(err))
+}
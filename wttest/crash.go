@@ -0,0 +1,167 @@
+// Package wttest provides helpers for testing code that uses WiredTiger.
+package wttest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+)
+
+// Environment variables that are set for the child process of a crash test.
+const (
+	crashDirEnv       = "WTTEST_CRASH_DIR"
+	crashIterationEnv = "WTTEST_CRASH_ITERATION"
+)
+
+// crashAckFd is the file descriptor that child process writes acknowledgements to.
+const crashAckFd = 3
+
+// CrashTest describes a crash test. Workload runs in a child process that gets killed
+// with SIGKILL at a random point. Database is then reopened and Check verifies that
+// all acknowledged commits have survived the crash.
+type CrashTest struct {
+	// Cfg is used to open the connection, both in the child process and for verification.
+	// It needs to have Create option set, for the first iteration to succeed.
+	Cfg wt.ConnCfg
+	// Workload runs in the child process until it is killed or until it returns. It must
+	// only call `ack` once commit is durable. Ids must not contain new lines. `iteration`
+	// can be used to generate ids that are unique across iterations.
+	Workload func(conn *wt.Connection, iteration int, ack func(id string)) error
+	// Check verifies the database after the crash. `acked` has ids that were acknowledged
+	// in all of the iterations so far, in the order of acknowledgement.
+	Check func(conn *wt.Connection, acked []string) error
+	// Iterations is the number of crash and recovery cycles. Defaults to 5.
+	Iterations int
+	// MaxKillDelay is the maximum time after which child process is killed. Defaults
+	// to 1 second.
+	MaxKillDelay time.Duration
+	// Seed for randomizing kill delays. If zero, random seed is used. Seed is always
+	// logged, to be able to reproduce failures.
+	Seed int64
+}
+
+// RunCrashTest runs a crash test. Child processes re-run the test binary with `-test.run`
+// flag that only matches t.Name(), thus it must be called from a top level test function
+// and it must be the first thing that the test does.
+func RunCrashTest(t *testing.T, ct CrashTest) {
+	if dir := os.Getenv(crashDirEnv); dir != "" {
+		runCrashChild(dir, ct)
+		return
+	}
+	iterations := ct.Iterations
+	if iterations <= 0 {
+		iterations = 5
+	}
+	maxKillDelay := ct.MaxKillDelay
+	if maxKillDelay <= 0 {
+		maxKillDelay = time.Second
+	}
+	seed := ct.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("crash test seed: %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	dir := t.TempDir()
+	var acked []string
+	for i := 0; i < iterations; i++ {
+		killDelay := time.Duration(rng.Int63n(int64(maxKillDelay)))
+		acks, err := runCrashIteration(t, dir, i, killDelay)
+		require.NoError(t, err, "iteration: %d", i)
+		acked = append(acked, acks...)
+
+		conn, err := wt.Open(dir, ct.Cfg)
+		require.NoError(t, err, "iteration: %d", i)
+		err = ct.Check(conn, acked)
+		require.NoError(t, conn.Close())
+		require.NoError(t, err, "iteration: %d, acked: %d", i, len(acked))
+	}
+}
+
+// runCrashIteration starts child process and kills it after `killDelay`. Returns ids that
+// child process has acknowledged.
+func runCrashIteration(t *testing.T, dir string, iteration int, killDelay time.Duration) ([]string, error) {
+	ackR, ackW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer ackR.Close()
+	cmd := exec.Command(os.Args[0], "-test.run=^"+regexp.QuoteMeta(t.Name())+"$")
+	cmd.Env = append(os.Environ(),
+		crashDirEnv+"="+dir,
+		crashIterationEnv+"="+strconv.Itoa(iteration))
+	cmd.ExtraFiles = []*os.File{ackW} // Becomes `crashAckFd` in the child.
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Start()
+	ackW.Close()
+	if err != nil {
+		return nil, err
+	}
+	acksC := make(chan []string, 1)
+	go func() {
+		var acks []string
+		scanner := bufio.NewScanner(ackR)
+		for scanner.Scan() {
+			acks = append(acks, scanner.Text())
+		}
+		acksC <- acks
+	}()
+	exitC := make(chan error, 1)
+	go func() { exitC <- cmd.Wait() }()
+	select {
+	case err := <-exitC:
+		if err != nil {
+			return nil, fmt.Errorf("child process failed: %w\n%s", err, output.String())
+		}
+	case <-time.After(killDelay):
+		if err := cmd.Process.Kill(); err != nil {
+			return nil, err
+		}
+		<-exitC
+	}
+	return <-acksC, nil
+}
+
+// runCrashChild runs the workload and exits the process.
+func runCrashChild(dir string, ct CrashTest) {
+	iteration, err := strconv.Atoi(os.Getenv(crashIterationEnv))
+	if err != nil {
+		crashChildFatal(err)
+	}
+	ackW := os.NewFile(crashAckFd, "acks")
+	ack := func(id string) {
+		if _, err := io.WriteString(ackW, id+"\n"); err != nil {
+			crashChildFatal(err)
+		}
+	}
+	conn, err := wt.Open(dir, ct.Cfg)
+	if err != nil {
+		crashChildFatal(err)
+	}
+	if err := ct.Workload(conn, iteration, ack); err != nil {
+		crashChildFatal(err)
+	}
+	if err := conn.Close(); err != nil {
+		crashChildFatal(err)
+	}
+	os.Exit(0)
+}
+
+func crashChildFatal(err error) {
+	fmt.Fprintln(os.Stderr, "crash test child:", err)
+	os.Exit(2)
+}
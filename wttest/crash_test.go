@@ -0,0 +1,112 @@
+package wttest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zviadm/wt"
+)
+
+const (
+	crashTable    = "table:crash"
+	crashTxKeys   = 10
+	crashTxValue  = "value"
+	crashTxPrefix = "tx"
+)
+
+// Each transaction writes `crashTxKeys` keys. Transactions are made durable either with
+// synchronous commits or with explicit log flushes.
+func crashWorkload(conn *wt.Connection, iteration int, ack func(id string)) error {
+	s, err := conn.OpenSession()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.Create(crashTable); err != nil {
+		return err
+	}
+	c, err := s.OpenCursor(crashTable)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	for txIdx := 0; txIdx < 100000; txIdx++ {
+		id := strconv.Itoa(iteration) + "-" + strconv.Itoa(txIdx)
+		if err := s.TxBegin(); err != nil {
+			return err
+		}
+		for i := 0; i < crashTxKeys; i++ {
+			key := crashTxPrefix + id + "/" + strconv.Itoa(i)
+			if err := c.Insert([]byte(key), []byte(crashTxValue)); err != nil {
+				return err
+			}
+		}
+		if txIdx%2 == 0 {
+			err = s.TxCommit(wt.TxCfg{Sync: wt.True})
+		} else if err = s.TxCommit(); err == nil {
+			err = s.LogFlush(wt.SyncOn)
+		}
+		if err != nil {
+			return err
+		}
+		ack(id)
+	}
+	return nil
+}
+
+// crashCheck verifies that all acknowledged transactions are present, and that all
+// transactions, acknowledged or not, are either fully applied or not applied at all.
+func crashCheck(conn *wt.Connection, acked []string) error {
+	s, err := conn.OpenSession()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	// Child process might have been killed before it created the table.
+	if err := s.Create(crashTable); err != nil {
+		return err
+	}
+	c, err := s.OpenCursor(crashTable)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	txKeys := make(map[string]int)
+	for {
+		if err := c.Next(); wt.ErrCode(err) == wt.ErrNotFound {
+			break
+		} else if err != nil {
+			return err
+		}
+		key, err := c.Key()
+		if err != nil {
+			return err
+		}
+		id := strings.SplitN(strings.TrimPrefix(string(key), crashTxPrefix), "/", 2)[0]
+		txKeys[id]++
+	}
+	for id, n := range txKeys {
+		if n != crashTxKeys {
+			return fmt.Errorf("transaction %s is partially applied: %d keys", id, n)
+		}
+	}
+	for _, id := range acked {
+		if txKeys[id] == 0 {
+			return fmt.Errorf("acknowledged transaction %s is missing", id)
+		}
+	}
+	return nil
+}
+
+func TestCrash(t *testing.T) {
+	RunCrashTest(t, CrashTest{
+		Cfg:          wt.ConnCfg{Create: wt.True, Log: "enabled"},
+		Workload:     crashWorkload,
+		Check:        crashCheck,
+		Iterations:   5,
+		MaxKillDelay: 2 * time.Second,
+	})
+}
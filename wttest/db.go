@@ -0,0 +1,140 @@
+package wttest
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+)
+
+// DefaultTable is the table that NewDB creates, unless DBOpts.Table is set.
+const DefaultTable = "table:test_table"
+
+// DBOpts describes options for NewDB call.
+type DBOpts struct {
+	// Cfg is used to open the connection. Create option is always set.
+	Cfg wt.ConnCfg
+	// InMemory opens an in-memory database, same as wt.Open("", ConnCfg{InMemory: True}).
+	InMemory bool
+	// Table is URI of the table to create. Defaults to DefaultTable.
+	Table string
+	// TableCfg is used to create the table.
+	TableCfg wt.DataSourceCfg
+	// Fixture is preloaded into the table, see LoadFixture for its format.
+	Fixture string
+}
+
+// DB is a temporary database, created with NewDB call.
+type DB struct {
+	Conn    *wt.Connection
+	Session *wt.Session
+	// Table is URI of the created table.
+	Table string
+	// Dir is directory of the database. Empty for in-memory databases.
+	Dir string
+}
+
+// NewDB opens a connection and a session, and creates a table. Everything is closed
+// and removed once test finishes. Test fails, if there are any cursors left open in the
+// DB.Session at that point.
+func NewDB(t testing.TB, opts ...DBOpts) *DB {
+	var o DBOpts
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	cfg := o.Cfg
+	cfg.Create = wt.True
+	db := &DB{Table: o.Table}
+	if db.Table == "" {
+		db.Table = DefaultTable
+	}
+	if o.InMemory {
+		cfg.InMemory = wt.True
+	} else {
+		db.Dir = t.TempDir()
+	}
+
+	var err error
+	db.Conn, err = wt.Open(db.Dir, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Conn.Close()) })
+	db.Session, err = db.Conn.OpenSession()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if n := db.Session.OpenCursors(); n > 0 {
+			t.Errorf("%d cursor(s) leaked", n)
+		}
+		require.NoError(t, db.Session.Close())
+	})
+	err = db.Session.Create(db.Table, o.TableCfg)
+	require.NoError(t, err)
+	if o.Fixture != "" {
+		err = LoadFixture(db.Session, db.Table, o.Fixture)
+		require.NoError(t, err)
+	}
+	return db
+}
+
+// LoadFixture inserts key/value pairs from `fixture` text into a data source. Each line has
+// a single key/value pair, separated by the first '=' character. Keys and values that are
+// in double quotes are unquoted using Go syntax, thus they can contain any bytes. Lines
+// are trimmed, and empty lines and lines that start with '#' are skipped. For example:
+//
+//	# Comment.
+//	key1=value1
+//	"key\x002"="value with = sign"
+func LoadFixture(s *wt.Session, uri string, fixture string) error {
+	c, err := s.OpenCursor(uri)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	scanner := bufio.NewScanner(strings.NewReader(fixture))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseFixtureLine(line)
+		if err != nil {
+			return fmt.Errorf("fixture line %d: %w", lineNo, err)
+		}
+		if err := c.Insert([]byte(key), []byte(value)); err != nil {
+			return fmt.Errorf("fixture line %d: %w", lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseFixtureLine(line string) (key, value string, err error) {
+	rest := line
+	if strings.HasPrefix(line, `"`) {
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return "", "", err
+		}
+		if key, err = strconv.Unquote(quoted); err != nil {
+			return "", "", err
+		}
+		rest = line[len(quoted):]
+	} else if idx := strings.Index(line, "="); idx >= 0 {
+		key, rest = line[:idx], line[idx:]
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", fmt.Errorf("missing '=': %q", line)
+	}
+	value = rest[1:]
+	if strings.HasPrefix(value, `"`) {
+		if value, err = strconv.Unquote(value); err != nil {
+			return "", "", err
+		}
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("empty key: %q", line)
+	}
+	return key, value, nil
+}
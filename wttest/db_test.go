@@ -0,0 +1,67 @@
+package wttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zviadm/wt"
+)
+
+func TestNewDB(t *testing.T) {
+	for _, inMemory := range []bool{false, true} {
+		db := NewDB(t, DBOpts{
+			InMemory: inMemory,
+			Fixture: `
+				# Comment.
+				key1=value1
+				key2=value with = sign
+				"key\x003"="quoted"
+			`,
+		})
+		c, err := db.Session.OpenCursor(db.Table)
+		require.NoError(t, err)
+		for key, value := range map[string]string{
+			"key1":     "value1",
+			"key2":     "value with = sign",
+			"key\x003": "quoted",
+		} {
+			v, err := c.ReadValue([]byte(key))
+			require.NoError(t, err)
+			require.EqualValues(t, value, string(v))
+		}
+		require.NoError(t, c.Close())
+		require.EqualValues(t, 0, db.Session.OpenCursors())
+	}
+}
+
+func TestParseFixtureLine(t *testing.T) {
+	key, value, err := parseFixtureLine(`a=b=c`)
+	require.NoError(t, err)
+	require.EqualValues(t, "a", key)
+	require.EqualValues(t, "b=c", value)
+	key, value, err = parseFixtureLine(`"a=b"=`)
+	require.NoError(t, err)
+	require.EqualValues(t, "a=b", key)
+	require.EqualValues(t, "", value)
+
+	for _, line := range []string{`abc`, `=value`, `"a"b=c`, `"a=b`, `a="b`} {
+		_, _, err := parseFixtureLine(line)
+		require.Error(t, err, line)
+	}
+}
+
+func TestNewDBTableCfg(t *testing.T) {
+	db := NewDB(t, DBOpts{
+		Cfg:      wt.ConnCfg{Log: "enabled"},
+		Table:    "table:typed",
+		TableCfg: wt.DataSourceCfg{KeyFormat: "S", ValueFormat: "q"},
+	})
+	c, err := db.Session.OpenTypedCursor(db.Table)
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Insert([]interface{}{"key"}, []interface{}{int64(42)}))
+	var v int64
+	require.NoError(t, c.Search("key"))
+	require.NoError(t, c.Value(&v))
+	require.EqualValues(t, 42, v)
+}